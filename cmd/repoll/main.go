@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -28,6 +29,40 @@ var (
 	quietFlag   bool
 )
 
+// Report flags of the run and mkconf commands
+var (
+	formatFlag     string
+	reportFileFlag string
+)
+
+// Run command flags
+var (
+	profileFlag     string
+	forceWarmUpFlag bool
+	jobsFlag        int
+	warmUpJobsFlag  int
+)
+
+// mkconf command flags
+var (
+	outputFlag   string
+	mergeFlag    bool
+	nestedFlag   bool
+	excludeFlags []string
+	maxDepthFlag int
+	linksFlag    bool
+	pinFlag      bool
+)
+
+// add/remove command flags
+var (
+	editConfigFlag string
+	renameFlag     string
+	tagFlags       []string
+	siteDirFlag    string
+	deleteDirFlag  bool
+)
+
 func main() {
 	rootCmd := &cobra.Command{
 		Use:   "repoll",
@@ -46,12 +81,17 @@ that revolutionizes how you manage multiple Git repositories.`,
 	// Main command for processing config files
 	runCmd := &cobra.Command{
 		Use:   "run [config-files...]",
-		Short: "Process configuration files to clone/update repositories",
+		Short: "Process configuration files (TOML, YAML, JSON, or - for stdin) to clone/update repositories",
 		Args:  cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runProcessConfigs(args)
 		},
 	}
+	runCmd.Flags().StringVar(&profileFlag, "profile", "", "Apply the named profile from the configuration")
+	runCmd.Flags().BoolVar(&forceWarmUpFlag, "force-warm-up", false, "Warm up even when dependency manifests are unchanged")
+	runCmd.Flags().IntVarP(&jobsFlag, "jobs", "j", process.DefaultFetchJobs, "Number of repositories cloned or updated in parallel")
+	runCmd.Flags().IntVar(&warmUpJobsFlag, "warm-up-jobs", process.DefaultWarmUpJobs, "Number of repositories warmed up in parallel")
+	addReportFlags(runCmd)
 
 	// mkconf command
 	mkconfCmd := &cobra.Command{
@@ -66,6 +106,14 @@ that revolutionizes how you manage multiple Git repositories.`,
 			return runMakeConfig(targetDir)
 		},
 	}
+	mkconfCmd.Flags().StringVarP(&outputFlag, "output", "o", "repos.toml", "Output file (TOML, YAML, JSON, or - for stdout)")
+	mkconfCmd.Flags().BoolVar(&mergeFlag, "merge", false, "Merge discovered repositories into the existing output file")
+	mkconfCmd.Flags().BoolVar(&nestedFlag, "nested", false, "Also search inside repositories for nested repositories")
+	mkconfCmd.Flags().StringSliceVar(&excludeFlags, "exclude", nil, "Skip paths matching the glob (repeatable)")
+	mkconfCmd.Flags().IntVar(&maxDepthFlag, "max-depth", 0, "Limit how many directory levels are searched (0 for no limit)")
+	mkconfCmd.Flags().BoolVar(&linksFlag, "record-links", false, "Record linked worktrees and submodule usage in the generated config")
+	mkconfCmd.Flags().BoolVar(&pinFlag, "pin-commits", false, "Record the exact commit of every repository for a reproducible snapshot")
+	addReportFlags(mkconfCmd)
 
 	// Legacy support: direct config file processing (no subcommand)
 	rootCmd.Run = func(cmd *cobra.Command, args []string) {
@@ -75,7 +123,7 @@ that revolutionizes how you manage multiple Git repositories.`,
 		}
 		
 		// Check if the first argument is a config file
-		if len(args) > 0 && strings.HasSuffix(args[0], ".toml") {
+		if len(args) > 0 && config.IsConfigFile(args[0]) {
 			err := runProcessConfigs(args)
 			if err != nil {
 				log.Fatal(err)
@@ -86,8 +134,61 @@ that revolutionizes how you manage multiple Git repositories.`,
 		cmd.Help()
 	}
 
+	// schema command
+	schemaCmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of the configuration file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSchema()
+		},
+	}
+
+	// validate command
+	validateCmd := &cobra.Command{
+		Use:   "validate [config-files...]",
+		Short: "Validate configuration files against the JSON Schema",
+		Args:  cobra.MinimumNArgs(1),
+		// Problems are already reported per file
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runValidate(args)
+		},
+	}
+
+	// add command
+	addCmd := &cobra.Command{
+		Use:   "add <url>",
+		Short: "Add a repository to a configuration file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAddRepo(args[0])
+		},
+	}
+	addCmd.Flags().StringVarP(&editConfigFlag, "config", "c", "repos.toml", "Configuration file to edit")
+	addCmd.Flags().StringVar(&renameFlag, "rename", "", "Local directory name of the repository")
+	addCmd.Flags().StringSliceVar(&tagFlags, "tag", nil, "Tag the repository (repeatable)")
+	addCmd.Flags().StringVar(&siteDirFlag, "dir", "./", "Directory of the site created when no site matches the URL")
+
+	// remove command
+	removeCmd := &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Remove a repository from a configuration file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRemoveRepo(args[0])
+		},
+	}
+	removeCmd.Flags().StringVarP(&editConfigFlag, "config", "c", "repos.toml", "Configuration file to edit")
+	removeCmd.Flags().BoolVar(&deleteDirFlag, "delete-dir", false, "Also delete the local clone of the repository")
+
 	rootCmd.AddCommand(runCmd)
 	rootCmd.AddCommand(mkconfCmd)
+	rootCmd.AddCommand(schemaCmd)
+	rootCmd.AddCommand(validateCmd)
+	rootCmd.AddCommand(addCmd)
+	rootCmd.AddCommand(removeCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -98,6 +199,9 @@ that revolutionizes how you manage multiple Git repositories.`,
 // runProcessConfigs processes multiple configuration files
 func runProcessConfigs(configPaths []string) error {
 	ui := cli.NewUIManager(quietFlag, verboseFlag)
+	if err := prepareReportOutput(ui, &reporter.MakeReport{}); err != nil {
+		return err
+	}
 	
 	if !quietFlag {
 		ui.Banner()
@@ -109,13 +213,17 @@ func runProcessConfigs(configPaths []string) error {
 	failCount := 0
 	
 	var report *reporter.MakeReport
-	if reportFlag {
+	if reportRequested() {
 		report = &reporter.MakeReport{}
 	}
 	
 	opts := &process.ProcessorOptions{
-		UI:     ui,
-		DryRun: dryRunFlag,
+		UI:          ui,
+		DryRun:      dryRunFlag,
+		Profile:     profileFlag,
+		ForceWarmUp: forceWarmUpFlag,
+		FetchJobs:   jobsFlag,
+		WarmUpJobs:  warmUpJobsFlag,
 	}
 	
 	if dryRunFlag {
@@ -127,31 +235,42 @@ func runProcessConfigs(configPaths []string) error {
 			ui.Section(fmt.Sprintf("Processing %s (%d/%d)", configPath, i+1, len(configPaths)))
 		}
 		
-		if _, err := os.Stat(configPath); os.IsNotExist(err) {
-			ui.Error("Configuration file not found: %s", configPath)
-			failCount++
-			continue
+		if configPath != config.StdinPath {
+			if _, err := os.Stat(configPath); os.IsNotExist(err) {
+				ui.Error("Configuration file not found: %s", configPath)
+				recordConfigFailure(report, configPath, fmt.Errorf("configuration file not found"))
+				failCount++
+				continue
+			}
 		}
 		
-		err := process.ProcessConfig(configPath, report, opts)
+		opts.ConfigDir = "."
+		if configPath != config.StdinPath {
+			opts.ConfigDir = filepath.Dir(configPath)
+		}
+		
+		ui.Info("Loading configuration from %s", configPath)
+		cfg, err := config.ReadFromFile(configPath)
+		if err == nil {
+			err = process.Process(cfg, report, opts)
+		}
 		if err != nil {
 			ui.Error("Failed to process %s: %v", configPath, err)
+			recordConfigFailure(report, configPath, err)
 			failCount++
 			continue
 		}
 		
 		// Count repositories from this config
-		if cfg, err := config.ReadFromFile(configPath); err == nil {
-			for _, site := range cfg.Sites {
-				totalRepos += len(site.Repos)
-			}
+		for _, site := range cfg.Sites {
+			totalRepos += len(site.Repos)
 		}
 	}
 	
 	// Calculate success/fail counts from report if available
 	if report != nil {
 		successCount = 0
-		failCount = 0
+		failCount = len(report.ConfigFailures)
 		for _, action := range report.Actions {
 			if action.Success {
 				successCount++
@@ -171,17 +290,34 @@ func runProcessConfigs(configPaths []string) error {
 	}
 	
 	// Generate report if requested
-	if reportFlag && report != nil {
+	if report != nil {
 		ui.Info("Generating execution report...")
-		fmt.Println(report.Report())
+		return writeReport(ui, report)
 	}
 	
 	return nil
 }
 
+// recordConfigFailure adds a configuration file that could not be processed to the report
+func recordConfigFailure(report *reporter.MakeReport, configPath string, err error) {
+	if report != nil {
+		report.ConfigFailures = append(report.ConfigFailures, reporter.ConfigFailure{Config: configPath, Error: err.Error()})
+	}
+}
+
 // runMakeConfig generates a configuration file from existing repositories
 func runMakeConfig(targetDir string) error {
 	ui := cli.NewUIManager(quietFlag, verboseFlag)
+	if err := prepareReportOutput(ui, &reporter.MkconfReport{}); err != nil {
+		return err
+	}
+	if structuredReportToStdout() && (dryRunFlag || outputFlag == config.StdinPath) {
+		return fmt.Errorf("the configuration and the %s report cannot both be written to stdout; use --report-file", formatFlag)
+	}
+	if outputFlag == config.StdinPath {
+		// Keep the configuration on stdout parseable
+		ui.SetOutput(os.Stderr)
+	}
 	
 	if !quietFlag {
 		ui.Banner()
@@ -196,7 +332,7 @@ func runMakeConfig(targetDir string) error {
 	}
 	
 	var report *reporter.MkconfReport
-	if reportFlag {
+	if reportRequested() {
 		report = &reporter.MkconfReport{}
 	}
 	
@@ -204,7 +340,14 @@ func runMakeConfig(targetDir string) error {
 		ui.Warning("DRY RUN MODE: Configuration will be printed to stdout only")
 	}
 	
-	cfg, err := config.GenerateFromDirectory(targetDir, report)
+	cfg, err := config.GenerateFromDirectoryWithOptions(targetDir, report, config.GenerateOptions{
+		Nested:      nestedFlag,
+		Exclude:     excludeFlags,
+		MaxDepth:    maxDepthFlag,
+		RecordLinks: linksFlag,
+		PinCommits:  pinFlag,
+		Warn:        ui.Warning,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to generate configuration: %w", err)
 	}
@@ -214,18 +357,40 @@ func runMakeConfig(targetDir string) error {
 		return nil
 	}
 	
-	// Convert config to TOML string
-	configContent, err := config.ToTOML(cfg)
-	if err != nil {
-		return fmt.Errorf("failed to convert configuration to TOML: %w", err)
+	outputFile := outputFlag
+	if outputFile == "" {
+		outputFile = "repos.toml"
+	}
+	
+	var content []byte
+	if mergeFlag && outputFile != config.StdinPath && fileExists(outputFile) {
+		result, err := config.MergeDiscovered(outputFile, cfg)
+		if err != nil {
+			return fmt.Errorf("failed to merge configuration: %w", err)
+		}
+		content = result.Content
+		
+		ui.Info("Merging into %s: %d added, %d already configured", outputFile, len(result.Added), result.Kept)
+		for _, repo := range result.Added {
+			ui.Verbose("  + %s", repo)
+		}
+		for _, repo := range result.Missing {
+			ui.Warning("Configured repository not found on disk: %s", repo)
+		}
+	} else {
+		content, err = config.Marshal(cfg, config.FormatFromPath(outputFile))
+		if err != nil {
+			return fmt.Errorf("failed to encode configuration: %w", err)
+		}
 	}
 	
 	if dryRunFlag {
 		ui.Section("Generated Configuration (DRY RUN)")
-		fmt.Println(configContent)
+		fmt.Println(string(content))
+	} else if outputFile == config.StdinPath {
+		fmt.Print(string(content))
 	} else {
-		outputFile := "repos.toml"
-		err = os.WriteFile(outputFile, []byte(configContent), 0644)
+		err = os.WriteFile(outputFile, content, 0644)
 		if err != nil {
 			return fmt.Errorf("failed to write configuration file: %w", err)
 		}
@@ -236,10 +401,167 @@ func runMakeConfig(targetDir string) error {
 	ui.Info("Configuration generation completed in %v", duration)
 	
 	// Generate report if requested
-	if reportFlag && report != nil {
+	if report != nil {
 		ui.Info("Generating discovery report...")
-		fmt.Println(report.Report())
+		return writeReport(ui, report)
 	}
 	
 	return nil
-} 
\ No newline at end of file
+} 
+
+// runSchema prints the JSON Schema generated from the configuration structs
+func runSchema() error {
+	schema, err := config.GenerateSchema().JSON()
+	if err != nil {
+		return err
+	}
+	fmt.Println(schema)
+	return nil
+}
+
+// runValidate validates configuration files against the JSON Schema
+func runValidate(configPaths []string) error {
+	ui := cli.NewUIManager(quietFlag, verboseFlag)
+	invalid := 0
+
+	for _, configPath := range configPaths {
+		errs, err := config.ValidateFile(configPath)
+		if err != nil {
+			ui.Error("%s: %v", configPath, err)
+			invalid++
+			continue
+		}
+
+		if len(errs) > 0 {
+			ui.Error("%s: %d problem(s) found", configPath, len(errs))
+			for _, validationErr := range errs {
+				ui.Error("  %s", validationErr.Error())
+			}
+			invalid++
+			continue
+		}
+
+		ui.Success("%s is valid", configPath)
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d configuration file(s) are invalid", invalid, len(configPaths))
+	}
+	return nil
+}
+
+// runAddRepo adds a repository URL to the configuration file
+func runAddRepo(url string) error {
+	ui := cli.NewUIManager(quietFlag, verboseFlag)
+
+	edit, err := config.AddRepo(editConfigFlag, url, config.Repo{Rename: renameFlag, Tags: tagFlags}, siteDirFlag)
+	if err != nil {
+		return err
+	}
+
+	if edit.NewSite {
+		ui.Info("Creating site %s (dir: %s)", edit.Site.RemotePrefix, edit.Site.Dir)
+	}
+
+	if dryRunFlag {
+		ui.DryRun("Would add %s to %s", edit.Repo.Repo, editConfigFlag)
+		fmt.Print(string(edit.Content))
+		return nil
+	}
+
+	if err := edit.Write(editConfigFlag); err != nil {
+		return err
+	}
+	ui.Success("Added %s to %s", edit.Repo.Repo, editConfigFlag)
+	return nil
+}
+
+// runRemoveRepo removes a repository from the configuration file
+func runRemoveRepo(name string) error {
+	ui := cli.NewUIManager(quietFlag, verboseFlag)
+
+	edit, err := config.RemoveRepo(editConfigFlag, name)
+	if err != nil {
+		return err
+	}
+	repoPath := edit.Repo.FullPath(edit.Site)
+
+	if dryRunFlag {
+		ui.DryRun("Would remove %s from %s", edit.Repo.Repo, editConfigFlag)
+		if deleteDirFlag {
+			ui.DryRun("Would delete %s", repoPath)
+		}
+		fmt.Print(string(edit.Content))
+		return nil
+	}
+
+	if err := edit.Write(editConfigFlag); err != nil {
+		return err
+	}
+	ui.Success("Removed %s from %s", edit.Repo.Repo, editConfigFlag)
+
+	if deleteDirFlag {
+		if err := os.RemoveAll(repoPath); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", repoPath, err)
+		}
+		ui.Success("Deleted %s", repoPath)
+	}
+	return nil
+}
+
+// fileExists reports whether a regular file exists at path
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// addReportFlags registers the report format flags of a command
+func addReportFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&formatFlag, "format", reporter.FormatText, "Report format: "+strings.Join(reporter.Formats, ", ")+" (implies --report unless text)")
+	cmd.Flags().StringVar(&reportFileFlag, "report-file", "", "Write the report to a file instead of stdout (implies --report)")
+}
+
+// reportRequested reports whether a report has to be collected
+func reportRequested() bool {
+	return reportFlag || reportFileFlag != "" || (formatFlag != "" && formatFlag != reporter.FormatText)
+}
+
+// structuredReportToStdout reports whether a machine-readable report is written to stdout
+func structuredReportToStdout() bool {
+	return reportFileFlag == "" && formatFlag != "" && formatFlag != reporter.FormatText
+}
+
+// prepareReportOutput checks that the report can be rendered in the requested format and moves
+// console output to stderr when stdout carries a machine-readable report
+func prepareReportOutput(ui *cli.UIManager, report reporter.Renderer) error {
+	if formatFlag != "" {
+		if err := reporter.CheckFormat(report, formatFlag); err != nil {
+			return err
+		}
+	}
+	if structuredReportToStdout() {
+		ui.SetOutput(os.Stderr)
+	}
+	return nil
+}
+
+// writeReport writes a report in the requested format to --report-file or stdout
+func writeReport(ui *cli.UIManager, report reporter.Renderer) error {
+	if reportFileFlag == "" {
+		return reporter.Write(os.Stdout, report, formatFlag)
+	}
+
+	file, err := os.Create(reportFileFlag)
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	if err := reporter.Write(file, report, formatFlag); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	ui.Success("Report written to %s", reportFileFlag)
+	return nil
+}
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -12,7 +15,7 @@ import (
 )
 
 func TestRunProcessConfigs_EmptyConfigs(t *testing.T) {
-	err := runProcessConfigs([]string{}, false)
+	err := runProcessConfigs([]string{})
 	if err != nil {
 		t.Errorf("runProcessConfigs with empty configs should not fail: %v", err)
 	}
@@ -20,7 +23,7 @@ func TestRunProcessConfigs_EmptyConfigs(t *testing.T) {
 
 func TestRunProcessConfigs_NonExistentFile(t *testing.T) {
 	// 测试不存在的配置文件
-	err := runProcessConfigs([]string{"non-existent-file.toml"}, false)
+	err := runProcessConfigs([]string{"non-existent-file.toml"})
 	// 函数不应该返回错误，但会输出错误信息
 	if err != nil {
 		t.Errorf("runProcessConfigs should handle non-existent files gracefully: %v", err)
@@ -52,7 +55,7 @@ memo = "Test repository"
 		t.Fatalf("Failed to create repos directory: %v", err)
 	}
 	
-	err = runProcessConfigs([]string{configFile}, false)
+	err = runProcessConfigs([]string{configFile})
 	if err != nil {
 		t.Errorf("runProcessConfigs failed: %v", err)
 	}
@@ -83,7 +86,10 @@ memo = "Test for report"
 		t.Fatalf("Failed to create repos directory: %v", err)
 	}
 	
-	err = runProcessConfigs([]string{configFile}, true)
+	reportFlag = true
+	defer func() { reportFlag = false }()
+	
+	err = runProcessConfigs([]string{configFile})
 	if err != nil {
 		t.Errorf("runProcessConfigs with report failed: %v", err)
 	}
@@ -129,7 +135,7 @@ directory = "` + tempDir + `/repos2/"
 	}
 
 	// 测试处理多个配置文件
-	err = runProcessConfigs([]string{configFile1, configFile2}, false)
+	err = runProcessConfigs([]string{configFile1, configFile2})
 	if err != nil {
 		t.Fatalf("runProcessConfigs failed: %v", err)
 	}
@@ -147,7 +153,7 @@ func TestRunProcessConfigs_InvalidConfig(t *testing.T) {
 	}
 	
 	// 应该继续处理，不会返回错误（只是打印错误）
-	err = runProcessConfigs([]string{configFile}, false)
+	err = runProcessConfigs([]string{configFile})
 	if err != nil {
 		t.Fatalf("runProcessConfigs should not fail for invalid config: %v", err)
 	}
@@ -157,14 +163,14 @@ func TestRunMakeConfig_EmptyDirectory(t *testing.T) {
 	tempDir := t.TempDir()
 
 	// 在空目录中运行应该返回错误
-	err := runMakeConfig(tempDir, false)
+	err := runMakeConfig(tempDir)
 	if err == nil {
 		t.Error("Expected error when running runMakeConfig on empty directory without Git repositories")
 	}
 }
 
 func TestRunMakeConfig_NonExistentDirectory(t *testing.T) {
-	err := runMakeConfig("/non/existent/directory", false)
+	err := runMakeConfig("/non/existent/directory")
 	if err == nil {
 		t.Error("Expected error for non-existent directory")
 	}
@@ -213,7 +219,7 @@ func TestRunMakeConfig_WithGitRepo(t *testing.T) {
 	}
 	
 	// 测试runMakeConfig
-	err = runMakeConfig(".", false)
+	err = runMakeConfig(".")
 	if err != nil {
 		t.Fatalf("runMakeConfig failed: %v", err)
 	}
@@ -226,7 +232,7 @@ func TestRunMakeConfig_WithGitRepo(t *testing.T) {
 	
 	configFound := false
 	for _, file := range files {
-		if strings.HasPrefix(file.Name(), "repoll-config-") && strings.HasSuffix(file.Name(), ".toml") {
+		if file.Name() == "repos.toml" {
 			configFound = true
 			break
 		}
@@ -277,12 +283,252 @@ func TestRunMakeConfig_WithReport(t *testing.T) {
 	}
 	
 	// 测试带报告的runMakeConfig
-	err = runMakeConfig(".", true)
+	reportFlag = true
+	defer func() { reportFlag = false }()
+	
+	err = runMakeConfig(".")
 	if err != nil {
 		t.Fatalf("runMakeConfig with report failed: %v", err)
 	}
 }
 
+func TestRunMakeConfig_ValidatesOutput(t *testing.T) {
+	if !isGitAvailable() {
+		t.Skip("Git not available, skipping test")
+	}
+
+	tempDir := t.TempDir()
+	repoDir := filepath.Join(tempDir, "projects", "test-repo")
+	for _, args := range [][]string{{"init", repoDir}, {"-C", repoDir, "remote", "add", "origin", "https://github.com/test/repo.git"}} {
+		if err := exec.Command("git", args...).Run(); err != nil {
+			t.Fatalf("git %v failed: %v", args, err)
+		}
+	}
+
+	for _, name := range []string{"repos.toml", "repos.yaml", "repos.json"} {
+		configFile := filepath.Join(tempDir, name)
+		outputFlag = configFile
+		err := runMakeConfig(filepath.Join(tempDir, "projects"))
+		outputFlag = ""
+		if err != nil {
+			t.Fatalf("runMakeConfig %s failed: %v", name, err)
+		}
+
+		// 生成的配置能通过 validate
+		if err := runValidate([]string{configFile}); err != nil {
+			t.Errorf("Generated %s does not validate: %v", name, err)
+		}
+	}
+
+	// TOML 不写出未设置的字段和空表
+	data, err := os.ReadFile(filepath.Join(tempDir, "repos.toml"))
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	for _, unset := range []string{`""`, "depth", "hooks", "[sites.defaults]"} {
+		if strings.Contains(string(data), unset) {
+			t.Errorf("Expected %s to be omitted:\n%s", unset, data)
+		}
+	}
+
+	// JSON 同样不写出空的 hooks 和 defaults 对象
+	data, err = os.ReadFile(filepath.Join(tempDir, "repos.json"))
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	for _, unset := range []string{`"hooks"`, `"defaults"`} {
+		if strings.Contains(string(data), unset) {
+			t.Errorf("Expected %s to be omitted:\n%s", unset, data)
+		}
+	}
+}
+
+func TestRunMakeConfig_JSONReportToStdout(t *testing.T) {
+	if !isGitAvailable() {
+		t.Skip("Git not available, skipping test")
+	}
+
+	tempDir := t.TempDir()
+	projects := filepath.Join(tempDir, "projects")
+	for _, args := range [][]string{
+		{"init", filepath.Join(projects, "service")},
+		{"-C", filepath.Join(projects, "service"), "remote", "add", "origin", "https://github.com/test/service.git"},
+		{"init", filepath.Join(projects, "noorigin")},
+	} {
+		if err := exec.Command("git", args...).Run(); err != nil {
+			t.Fatalf("git %v failed: %v", args, err)
+		}
+	}
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	originalStdout := os.Stdout
+	os.Stdout = writer
+	formatFlag, outputFlag = "json", filepath.Join(tempDir, "repos.toml")
+	err = runMakeConfig(projects)
+	os.Stdout = originalStdout
+	formatFlag, outputFlag = "", ""
+	writer.Close()
+	stdout, _ := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("runMakeConfig failed: %v", err)
+	}
+
+	// 跳过仓库的提示不写入标准输出，JSON 报告保持有效
+	if !json.Valid(stdout) || strings.Contains(string(stdout), "Skipping") {
+		t.Errorf("Expected only the JSON report on stdout, got:\n%s", stdout)
+	}
+}
+
+func TestRunMakeConfig_MarkdownReportFile(t *testing.T) {
+	if !isGitAvailable() {
+		t.Skip("Git not available, skipping test")
+	}
+
+	tempDir := t.TempDir()
+	repoDir := filepath.Join(tempDir, "test-repo")
+	for _, args := range [][]string{{"init", repoDir}, {"-C", repoDir, "remote", "add", "origin", "https://github.com/test/repo.git"}} {
+		if err := exec.Command("git", args...).Run(); err != nil {
+			t.Fatalf("git %v failed: %v", args, err)
+		}
+	}
+
+	reportFile := filepath.Join(tempDir, "report.md")
+	formatFlag, reportFileFlag, outputFlag = "markdown", reportFile, filepath.Join(tempDir, "repos.toml")
+	defer func() { formatFlag, reportFileFlag, outputFlag = "", "", "" }()
+
+	if err := runMakeConfig(tempDir); err != nil {
+		t.Fatalf("runMakeConfig failed: %v", err)
+	}
+
+	// Markdown 报告按 origin 的站点分组
+	data, err := os.ReadFile(reportFile)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	if !strings.Contains(string(data), "## https://github.com/\n") || !strings.Contains(string(data), "https://github.com/test/repo.git | clean |") {
+		t.Errorf("Unexpected report:\n%s", data)
+	}
+}
+
+func TestRunMakeConfig_Merge(t *testing.T) {
+	if !isGitAvailable() {
+		t.Skip("Git not available, skipping test")
+	}
+	
+	tempDir := t.TempDir()
+	
+	repoDir := filepath.Join(tempDir, "repo")
+	err := os.MkdirAll(repoDir, 0755)
+	if err != nil {
+		t.Fatalf("Failed to create repo directory: %v", err)
+	}
+	
+	cmd := exec.Command("git", "init")
+	cmd.Dir = repoDir
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
+	
+	cmd = exec.Command("git", "remote", "add", "origin", "https://github.com/test/repo.git")
+	cmd.Dir = repoDir
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to add origin: %v", err)
+	}
+	
+	// 已有配置中手写的 memo 应在合并后保留
+	outputFile := filepath.Join(tempDir, "curated.toml")
+	existing := `[[sites]]
+    remote = "https://github.com/"
+    dir = "./"
+
+    [[sites.repos]]
+        repo = "test/repo"
+        memo = "hand written"
+`
+	if err := os.WriteFile(outputFile, []byte(existing), 0644); err != nil {
+		t.Fatalf("Failed to create existing config: %v", err)
+	}
+	
+	outputFlag = outputFile
+	mergeFlag = true
+	defer func() { outputFlag, mergeFlag = "", false }()
+	
+	if err := runMakeConfig(tempDir); err != nil {
+		t.Fatalf("runMakeConfig failed: %v", err)
+	}
+	
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read merged config: %v", err)
+	}
+	if string(content) != existing {
+		t.Errorf("Merged config changed unexpectedly:\n%s", content)
+	}
+}
+
+func TestMkconf_RoundTrip(t *testing.T) {
+	if !isGitAvailable() {
+		t.Skip("Git not available, skipping test")
+	}
+	
+	tempDir := t.TempDir()
+	upstream := filepath.Join(tempDir, "upstream")
+	workspace := filepath.Join(tempDir, "workspace")
+	
+	// 本地上游仓库，通过 file:// 克隆到各种目录布局中
+	layout := map[string]string{
+		"apps/api":       "team/api",
+		"apps/web-local": "team/web",
+		"libs/api":       "vendor/api",
+		"tools/cli":      "team/cli",
+	}
+	for dir, name := range layout {
+		source := filepath.Join(upstream, name+".git")
+		runGitCommand(t, "", "init", "-q", source)
+		runGitCommand(t, source, "commit", "-q", "--allow-empty", "-m", "initial")
+		runGitCommand(t, "", "clone", "-q", "file://"+source, filepath.Join(workspace, dir))
+	}
+	
+	outputFlag = filepath.Join(tempDir, "repos.toml")
+	defer func() { outputFlag = "" }()
+	
+	if err := runMakeConfig(workspace); err != nil {
+		t.Fatalf("runMakeConfig failed: %v", err)
+	}
+	
+	// 在空目录中运行生成的配置，应得到相同的目录布局
+	regenerated := filepath.Join(tempDir, "regenerated")
+	if err := os.MkdirAll(regenerated, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+	defer os.Chdir(originalDir)
+	if err := os.Chdir(regenerated); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	
+	if err := runProcessConfigs([]string{outputFlag}); err != nil {
+		t.Fatalf("runProcessConfigs failed: %v", err)
+	}
+	
+	for dir, name := range layout {
+		out, err := exec.Command("git", "-C", filepath.Join(regenerated, dir), "remote", "get-url", "origin").Output()
+		if err != nil {
+			t.Errorf("Repository %s was not recreated: %v", dir, err)
+			continue
+		}
+		if got, want := strings.TrimSpace(string(out)), "file://"+filepath.Join(upstream, name+".git"); got != want {
+			t.Errorf("Expected %s to track %s, got %s", dir, want, got)
+		}
+	}
+}
+
 func TestGlobalVariables(t *testing.T) {
 	// 验证全局变量不为空
 	if version == "" {
@@ -322,9 +568,131 @@ func TestVersionString(t *testing.T) {
 	}
 }
 
+// 辅助函数：运行Git命令
+func runGitCommand(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+}
+
 // 辅助函数：检查Git是否可用
 func isGitAvailable() bool {
 	cmd := exec.Command("git", "version")
 	err := cmd.Run()
 	return err == nil
-} 
\ No newline at end of file
+} 
+func TestRunProcessConfigs_JSONReportFile(t *testing.T) {
+	tempDir := t.TempDir()
+	configFile := filepath.Join(tempDir, "test.toml")
+	configContent := `[[sites]]
+remote = "file://` + tempDir + `/missing/"
+dir = "` + tempDir + `/repos/"
+
+[[sites.repos]]
+repo = "service"
+`
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config: %v", err)
+	}
+
+	reportFile := filepath.Join(tempDir, "report.json")
+	formatFlag, reportFileFlag = "json", reportFile
+	defer func() { formatFlag, reportFileFlag = "", "" }()
+
+	if err := runProcessConfigs([]string{configFile}); err != nil {
+		t.Fatalf("runProcessConfigs failed: %v", err)
+	}
+
+	// --format json 不需要 --report 也会生成报告，失败的仓库带有错误和路径
+	data, err := os.ReadFile(reportFile)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	var report struct {
+		SchemaVersion int `json:"schema_version"`
+		Actions       []struct {
+			Repository string `json:"repository"`
+			Site       string `json:"site"`
+			Path       string `json:"path"`
+			Kind       string `json:"kind"`
+			Success    bool   `json:"success"`
+			Error      string `json:"error"`
+		} `json:"actions"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Invalid JSON report: %v\n%s", err, data)
+	}
+	if report.SchemaVersion != 1 || len(report.Actions) != 1 {
+		t.Fatalf("Unexpected report:\n%s", data)
+	}
+	action := report.Actions[0]
+	if action.Repository != "service" || action.Site != "file://"+tempDir+"/missing/" || action.Kind != "clone" || action.Success || action.Error == "" ||
+		action.Path != filepath.Join(tempDir, "repos", "service") {
+		t.Errorf("Unexpected action: %+v", action)
+	}
+}
+
+func TestRunProcessConfigs_JUnitConfigFailures(t *testing.T) {
+	tempDir := t.TempDir()
+	brokenFile := filepath.Join(tempDir, "broken.toml")
+	if err := os.WriteFile(brokenFile, []byte("[[sites"), 0644); err != nil {
+		t.Fatalf("Failed to create test config: %v", err)
+	}
+	missingFile := filepath.Join(tempDir, "missing.toml")
+
+	reportFile := filepath.Join(tempDir, "report.xml")
+	formatFlag, reportFileFlag = "junit", reportFile
+	defer func() { formatFlag, reportFileFlag = "", "" }()
+
+	if err := runProcessConfigs([]string{missingFile, brokenFile}); err != nil {
+		t.Fatalf("runProcessConfigs failed: %v", err)
+	}
+
+	// 缺失和无法解析的配置文件都作为 JUnit 错误出现
+	data, err := os.ReadFile(reportFile)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	var report struct {
+		Errors int `xml:"errors,attr"`
+		Cases  []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"testsuite>testcase"`
+	}
+	if err := xml.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Invalid JUnit report: %v\n%s", err, data)
+	}
+	if report.Errors != 2 || len(report.Cases) != 2 || report.Cases[0].Name != missingFile || report.Cases[1].Name != brokenFile {
+		t.Errorf("Unexpected report:\n%s", data)
+	}
+}
+
+func TestReportFlags_Validation(t *testing.T) {
+	// 不支持的格式在处理前报错
+	formatFlag = "xml"
+	err := runProcessConfigs([]string{"repos.toml"})
+	formatFlag = ""
+	if err == nil || !strings.Contains(err.Error(), `unsupported report format "xml"`) {
+		t.Errorf("Expected unsupported format error, got %v", err)
+	}
+
+	// mkconf 没有 JUnit 报告
+	formatFlag = "junit"
+	err = runMakeConfig(t.TempDir())
+	formatFlag = ""
+	if err == nil || !strings.Contains(err.Error(), "junit reports are not available") {
+		t.Errorf("Expected junit format error, got %v", err)
+	}
+
+	// 配置和结构化报告不能同时写到标准输出
+	formatFlag, outputFlag = "ndjson", "-"
+	defer func() { formatFlag, outputFlag = "", "" }()
+	err = runMakeConfig(t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), "--report-file") {
+		t.Errorf("Expected stdout conflict error, got %v", err)
+	}
+}
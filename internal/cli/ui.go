@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -38,6 +39,7 @@ type UIManager struct {
 	colors *ColorProfile
 	quiet  bool
 	verbose bool
+	out     io.Writer
 }
 
 // NewUIManager creates a new UI manager
@@ -56,6 +58,19 @@ func NewUIManager(quiet, verbose bool) *UIManager {
 	}
 }
 
+// SetOutput redirects console output, e.g. to stderr when stdout carries a machine-readable report
+func (ui *UIManager) SetOutput(w io.Writer) {
+	ui.out = w
+}
+
+// output returns the writer console output goes to (stdout by default)
+func (ui *UIManager) output() io.Writer {
+	if ui.out == nil {
+		return os.Stdout
+	}
+	return ui.out
+}
+
 // isTerminal checks if stdout is a terminal
 func isTerminal() bool {
 	stat, _ := os.Stdout.Stat()
@@ -68,13 +83,13 @@ func (ui *UIManager) Success(format string, args ...interface{}) {
 		return
 	}
 	message := fmt.Sprintf(format, args...)
-	ui.colors.Success.Printf("✓ %s\n", message)
+	ui.colors.Success.Fprintf(ui.output(), "✓ %s\n", message)
 }
 
 // Error prints an error message with X mark
 func (ui *UIManager) Error(format string, args ...interface{}) {
 	message := fmt.Sprintf(format, args...)
-	ui.colors.Error.Printf("✗ %s\n", message)
+	ui.colors.Error.Fprintf(ui.output(), "✗ %s\n", message)
 }
 
 // Warning prints a warning message with warning symbol
@@ -83,7 +98,7 @@ func (ui *UIManager) Warning(format string, args ...interface{}) {
 		return
 	}
 	message := fmt.Sprintf(format, args...)
-	ui.colors.Warning.Printf("⚠ %s\n", message)
+	ui.colors.Warning.Fprintf(ui.output(), "⚠ %s\n", message)
 }
 
 // Info prints an informational message
@@ -92,7 +107,7 @@ func (ui *UIManager) Info(format string, args ...interface{}) {
 		return
 	}
 	message := fmt.Sprintf(format, args...)
-	ui.colors.Info.Printf("ℹ %s\n", message)
+	ui.colors.Info.Fprintf(ui.output(), "ℹ %s\n", message)
 }
 
 // Progress prints a progress message with spinner-like indicator
@@ -101,7 +116,7 @@ func (ui *UIManager) Progress(format string, args ...interface{}) {
 		return
 	}
 	message := fmt.Sprintf(format, args...)
-	ui.colors.Progress.Printf("⟳ %s\n", message)
+	ui.colors.Progress.Fprintf(ui.output(), "⟳ %s\n", message)
 }
 
 // Verbose prints a message only in verbose mode
@@ -110,7 +125,7 @@ func (ui *UIManager) Verbose(format string, args ...interface{}) {
 		return
 	}
 	message := fmt.Sprintf(format, args...)
-	ui.colors.Muted.Printf("  %s\n", message)
+	ui.colors.Muted.Fprintf(ui.output(), "  %s\n", message)
 }
 
 // Highlight prints a highlighted message
@@ -119,7 +134,7 @@ func (ui *UIManager) Highlight(format string, args ...interface{}) {
 		return
 	}
 	message := fmt.Sprintf(format, args...)
-	ui.colors.Highlight.Printf("★ %s\n", message)
+	ui.colors.Highlight.Fprintf(ui.output(), "★ %s\n", message)
 }
 
 // Section prints a section header
@@ -128,9 +143,9 @@ func (ui *UIManager) Section(title string) {
 		return
 	}
 	separator := strings.Repeat("─", len(title)+4)
-	ui.colors.Highlight.Printf("\n┌%s┐\n", separator)
-	ui.colors.Highlight.Printf("│ %s │\n", strings.ToUpper(title))
-	ui.colors.Highlight.Printf("└%s┘\n", separator)
+	ui.colors.Highlight.Fprintf(ui.output(), "\n┌%s┐\n", separator)
+	ui.colors.Highlight.Fprintf(ui.output(), "│ %s │\n", strings.ToUpper(title))
+	ui.colors.Highlight.Fprintf(ui.output(), "└%s┘\n", separator)
 }
 
 // ProcessingRepo shows repository processing status
@@ -138,7 +153,7 @@ func (ui *UIManager) ProcessingRepo(repo, action string) {
 	if ui.quiet {
 		return
 	}
-	ui.colors.Progress.Printf("⟳ %s %s...\n", action, ui.colors.Highlight.Sprint(repo))
+	ui.colors.Progress.Fprintf(ui.output(), "⟳ %s %s...\n", action, ui.colors.Highlight.Sprint(repo))
 }
 
 // RepoResult shows the result of repository processing
@@ -151,11 +166,11 @@ func (ui *UIManager) RepoResult(repo string, success bool, duration time.Duratio
 	repoStr := ui.colors.Highlight.Sprint(repo)
 	
 	if success {
-		ui.colors.Success.Printf("✓ %s %s\n", repoStr, durationStr)
+		ui.colors.Success.Fprintf(ui.output(), "✓ %s %s\n", repoStr, durationStr)
 	} else {
-		ui.colors.Error.Printf("✗ %s %s\n", repoStr, durationStr)
+		ui.colors.Error.Fprintf(ui.output(), "✗ %s %s\n", repoStr, durationStr)
 		if err != nil {
-			ui.colors.Error.Printf("  Error: %v\n", err)
+			ui.colors.Error.Fprintf(ui.output(), "  Error: %v\n", err)
 		}
 	}
 }
@@ -166,30 +181,30 @@ func (ui *UIManager) Summary(total, successful, failed int, totalDuration time.D
 		return
 	}
 	
-	fmt.Println()
+	fmt.Fprintln(ui.output())
 	ui.Section("Summary")
 	
-	ui.colors.Info.Printf("Total repositories: %d\n", total)
+	ui.colors.Info.Fprintf(ui.output(), "Total repositories: %d\n", total)
 	
 	if successful > 0 {
-		ui.colors.Success.Printf("Successful: %d\n", successful)
+		ui.colors.Success.Fprintf(ui.output(), "Successful: %d\n", successful)
 	}
 	
 	if failed > 0 {
-		ui.colors.Error.Printf("Failed: %d\n", failed)
+		ui.colors.Error.Fprintf(ui.output(), "Failed: %d\n", failed)
 	}
 	
-	ui.colors.Info.Printf("Total time: %v\n", totalDuration)
+	ui.colors.Info.Fprintf(ui.output(), "Total time: %v\n", totalDuration)
 	
 	if failed == 0 && total > 0 {
-		ui.colors.Success.Printf("\n🎉 All repositories processed successfully!\n")
+		ui.colors.Success.Fprintf(ui.output(), "\n🎉 All repositories processed successfully!\n")
 	}
 }
 
 // DryRun prints a dry run message
 func (ui *UIManager) DryRun(format string, args ...interface{}) {
 	message := fmt.Sprintf(format, args...)
-	ui.colors.Warning.Printf("[DRY RUN] %s\n", message)
+	ui.colors.Warning.Fprintf(ui.output(), "[DRY RUN] %s\n", message)
 }
 
 // Banner prints the repoll banner
@@ -201,7 +216,7 @@ func (ui *UIManager) Banner() {
 	banner := `
 🚀 repoll - Git Repository Management Tool
 `
-	ui.colors.Highlight.Print(banner)
+	ui.colors.Highlight.Fprint(ui.output(), banner)
 }
 
 // ProgressBar represents a simple progress indicator
@@ -240,17 +255,18 @@ func (pb *ProgressBar) Update(current int) {
 	
 	bar := strings.Repeat("█", filled) + strings.Repeat("░", pb.width-filled)
 	
-	fmt.Printf("\r%s [%s] %d/%d (%.1f%%)", 
+	fmt.Fprintf(pb.ui.output(), "\r%s [%s] %d/%d (%.1f%%)", 
 		pb.prefix, bar, current, pb.total, percent*100)
 	
 	if current >= pb.total {
-		fmt.Println()
+		fmt.Fprintln(pb.ui.output())
 	}
 }
 
 // Finish completes the progress bar
 func (pb *ProgressBar) Finish() {
-	if pb.ui.quiet {
+	// A bar that already reached its total has been completed by Update
+	if pb.ui.quiet || pb.current >= pb.total {
 		return
 	}
 	pb.Update(pb.total)
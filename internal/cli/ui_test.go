@@ -1,7 +1,9 @@
 package cli
 
 import (
+	"bytes"
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -164,6 +166,22 @@ func TestProgressBar_QuietMode(t *testing.T) {
 	pb.Finish()
 }
 
+func TestProgressBar_FinishAfterComplete(t *testing.T) {
+	ui := NewUIManager(false, false)
+	var buf bytes.Buffer
+	ui.SetOutput(&buf)
+
+	// 已经走满的进度条结束时不再重复输出
+	pb := ui.NewProgressBar(2, "Processing")
+	pb.Update(1)
+	pb.Update(2)
+	pb.Finish()
+
+	if got := strings.Count(buf.String(), "2/2"); got != 1 {
+		t.Errorf("Expected the completed bar to be printed once, got %d in %q", got, buf.String())
+	}
+}
+
 func TestUIManager_DryRun(t *testing.T) {
 	ui := NewUIManager(false, false)
 	
@@ -180,4 +198,22 @@ func TestIsTerminal(t *testing.T) {
 	if result != true && result != false {
 		t.Error("isTerminal should return a boolean value")
 	}
-} 
\ No newline at end of file
+} 
+func TestUIManager_SetOutput(t *testing.T) {
+	ui := NewUIManager(false, false)
+	var buf bytes.Buffer
+	ui.SetOutput(&buf)
+
+	// 重定向后所有输出（包括进度条）写入指定的 writer
+	ui.Info("loading %s", "repos.toml")
+	ui.Error("failed")
+	bar := ui.NewProgressBar(2, "Processing")
+	bar.Finish()
+
+	output := buf.String()
+	for _, expected := range []string{"loading repos.toml", "failed", "2/2"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got %q", expected, output)
+		}
+	}
+}
@@ -2,71 +2,115 @@ package config
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
-
-	"github.com/BurntSushi/toml"
 )
 
 // Config represents the complete configuration structure
 type Config struct {
-	Sites []SiteConfig `toml:"sites"`
+	Sites    []SiteConfig       `toml:"sites" yaml:"sites" json:"sites" desc:"Sites grouping repositories by remote prefix" jsonschema:"required"`
+	Profiles map[string]Profile `toml:"profiles" yaml:"profiles,omitempty" json:"profiles,omitempty" desc:"Named overrides selected with run --profile"`
 }
 
 // SiteConfig represents a site configuration with repositories
 type SiteConfig struct {
-	RemotePrefix string `toml:"remote"`
-	Dir          string `toml:"dir"`
-	Repos        []Repo `toml:"repos"`
-	WarmUpAll    bool   `toml:"warm_up_all"`
+	RemotePrefix string        `toml:"remote" yaml:"remote" json:"remote" desc:"URL prefix of the repositories, e.g. https://github.com/" jsonschema:"required"`
+	Dir          string        `toml:"dir" yaml:"dir" json:"dir" desc:"Local directory the repositories are cloned into" jsonschema:"required"`
+	Repos        []Repo        `toml:"repos" yaml:"repos" json:"repos" desc:"Repositories of this site"`
+	WarmUpAll    bool          `toml:"warm_up_all" yaml:"warm_up_all,omitempty" json:"warm_up_all,omitempty" desc:"Warm up every repository of this site"`
+	Defaults     *SiteDefaults `toml:"defaults" yaml:"defaults,omitempty" json:"defaults,omitempty" desc:"Settings inherited by every repository of this site"`
+	Expand       *Expand       `toml:"expand" yaml:"expand,omitempty" json:"expand,omitempty" desc:"Generate repositories from a listing file or mirror directory"`
 }
 
 // Repo represents a single repository configuration
 type Repo struct {
-	Repo   string `toml:"repo"`
-	Rename string `toml:"rename"`
-	WarmUp bool   `toml:"warm_up"`
-	Memo   string `toml:"memo"`
+	Repo   string   `toml:"repo" yaml:"repo" json:"repo" desc:"Repository path relative to the remote prefix, e.g. owner/name" jsonschema:"required"`
+	Rename string   `toml:"rename" yaml:"rename,omitempty" json:"rename,omitempty" desc:"Local directory name (defaults to the repository name)"`
+	WarmUp bool     `toml:"warm_up" yaml:"warm_up,omitempty" json:"warm_up,omitempty" desc:"Warm up the repository after clone or update"`
+	Memo   string   `toml:"memo" yaml:"memo,omitempty" json:"memo,omitempty" desc:"Description of the repository"`
+	Depth  *int     `toml:"depth" yaml:"depth,omitempty" json:"depth,omitempty" desc:"Shallow clone depth (0 for a full clone, overriding the site default)" jsonschema:"minimum=0"`
+	Update string   `toml:"update" yaml:"update,omitempty" json:"update,omitempty" desc:"Update strategy for existing clones" jsonschema:"enum=pull|rebase|ff-only|fetch|skip"`
+	Tags   []string `toml:"tags" yaml:"tags,omitempty" json:"tags,omitempty" desc:"Labels used by profile tag selection"`
+	Branch *string  `toml:"branch" yaml:"branch,omitempty" json:"branch,omitempty" desc:"Branch or tag checked out when cloning (empty for the remote default, overriding the site default)"`
+	Commit string   `toml:"commit" yaml:"commit,omitempty" json:"commit,omitempty" desc:"Exact commit to check out; pins the repository instead of pulling"`
+
+	WarmUpCommands [][]string   `toml:"warm_up_commands" yaml:"warm_up_commands,omitempty" json:"warm_up_commands,omitempty" desc:"Commands run instead of the auto-detected warm-up"`
+	WarmUpSteps    []WarmUpStep `toml:"warm_up_steps" yaml:"warm_up_steps,omitempty" json:"warm_up_steps,omitempty" desc:"Warm-up commands with their own directory, environment and timeout, run after warm_up_commands"`
+	WarmUpMode     string       `toml:"warm_up_mode" yaml:"warm_up_mode,omitempty" json:"warm_up_mode,omitempty" desc:"Whether custom warm-up commands replace or extend the auto-detected warm-up" jsonschema:"enum=replace|extend"`
+	WarmUpMutable  *bool        `toml:"warm_up_mutable" yaml:"warm_up_mutable,omitempty" json:"warm_up_mutable,omitempty" desc:"Keep changes warm-up makes to tracked files instead of reverting them (false overrides the site default)"`
+	Hooks          *Hooks       `toml:"hooks" yaml:"hooks,omitempty" json:"hooks,omitempty" desc:"Commands run after git operations"`
+	Submodules     bool         `toml:"submodules" yaml:"submodules,omitempty" json:"submodules,omitempty" desc:"Clone and update the repository's submodules"`
+	Worktrees      []Worktree   `toml:"worktrees" yaml:"worktrees,omitempty" json:"worktrees,omitempty" desc:"Linked worktrees created next to the clone"`
 }
 
-// ReadFromFile reads and parses a TOML configuration file
-func ReadFromFile(configPath string) (*Config, error) {
-	var config Config
+// Worktree represents a linked worktree of a repository
+type Worktree struct {
+	Path   string `toml:"path" yaml:"path" json:"path" desc:"Worktree directory, relative to the repository directory" jsonschema:"required"`
+	Branch string `toml:"branch" yaml:"branch,omitempty" json:"branch,omitempty" desc:"Branch or commit checked out in the worktree"`
+}
 
-	data, err := os.ReadFile(configPath)
+// ReadFromFile reads and parses a configuration file.
+// The format is chosen from the file extension; StdinPath reads from standard input
+// and detects the format from its content.
+func ReadFromFile(configPath string) (*Config, error) {
+	data, format, err := readConfigData(configPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return nil, err
 	}
 
-	err = toml.Unmarshal(data, &config)
+	return Parse(data, format)
+}
+
+// readConfigData reads raw configuration data and determines its format
+func readConfigData(configPath string) ([]byte, string, error) {
+	var data []byte
+	var err error
+	format := FormatFromPath(configPath)
+
+	if configPath == StdinPath {
+		data, err = io.ReadAll(stdin)
+		format = detectFormat(data)
+	} else {
+		data, err = os.ReadFile(configPath)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse TOML: %w", err)
+		return nil, "", fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	return &config, nil
+	return data, format, nil
 }
 
-// SaveToFile saves a configuration structure to a TOML file
+// SaveToFile saves a configuration structure to a file in the format matching its extension.
+// StdinPath writes TOML to standard output.
 func SaveToFile(config Config, filename string) error {
-	file, err := os.Create(filename)
+	data, err := Marshal(&config, FormatFromPath(filename))
 	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+		return err
 	}
-	defer file.Close()
 
-	encoder := toml.NewEncoder(file)
-	err = encoder.Encode(config)
+	if filename == StdinPath {
+		_, err = stdout.Write(data)
+		return err
+	}
+
+	err = os.WriteFile(filename, data, 0644)
 	if err != nil {
-		return fmt.Errorf("failed to encode TOML: %w", err)
+		return fmt.Errorf("failed to create file: %w", err)
 	}
 
 	return nil
 }
 
-// RepoUrl generates the complete Git repository URL for cloning
+// RepoUrl generates the complete Git repository URL for cloning.
+// SSH prefixes such as git@github.com: are joined without a slash.
 func (repo Repo) RepoUrl(site SiteConfig) string {
 	url := strings.TrimSuffix(site.RemotePrefix, "/") + "/" + repo.Repo
+	if strings.HasSuffix(site.RemotePrefix, ":") {
+		url = site.RemotePrefix + repo.Repo
+	}
 	if !strings.HasSuffix(url, ".git") {
 		url += ".git"
 	}
@@ -110,35 +154,219 @@ func ToTOML(cfg *Config) (string, error) {
 			builder.WriteString("\n")
 		}
 		
-		builder.WriteString("[[sites]]\n")
-		builder.WriteString(fmt.Sprintf("    remote_prefix = %q\n", site.RemotePrefix))
-		builder.WriteString(fmt.Sprintf("    dir = %q\n", site.Dir))
-		
-		if site.WarmUpAll {
-			builder.WriteString("    warm_up_all = true\n")
+		writeSite(&builder, site)
+	}
+
+	writeProfiles(&builder, cfg)
+	
+	return builder.String(), nil
+} 
+
+// writeSite appends a [[sites]] table and its repositories to a TOML builder
+func writeSite(builder *strings.Builder, site SiteConfig) {
+	builder.WriteString("[[sites]]\n")
+	builder.WriteString(fmt.Sprintf("    remote = %q\n", site.RemotePrefix))
+	builder.WriteString(fmt.Sprintf("    dir = %q\n", site.Dir))
+	
+	if site.WarmUpAll {
+		builder.WriteString("    warm_up_all = true\n")
+	}
+
+	if site.Expand != nil {
+		builder.WriteString(fmt.Sprintf("    expand = { from = %q", site.Expand.From))
+		if site.Expand.RepoGlob != "" {
+			builder.WriteString(fmt.Sprintf(", repo_glob = %q", site.Expand.RepoGlob))
 		}
-		
+		builder.WriteString(" }\n")
+	}
+	
+	builder.WriteString("\n")
+
+	writeSiteDefaults(builder, site.Defaults)
+
+	for _, repo := range site.Repos {
+		writeRepo(builder, repo, "    ", "        ")
 		builder.WriteString("\n")
-		
-		for _, repo := range site.Repos {
-			builder.WriteString("    [[sites.repos]]\n")
-			builder.WriteString(fmt.Sprintf("        repo = %q\n", repo.Repo))
-			
-			if repo.Rename != "" {
-				builder.WriteString(fmt.Sprintf("        rename = %q\n", repo.Rename))
-			}
-			
-			if repo.WarmUp {
-				builder.WriteString("        warm_up = true\n")
+	}
+}
+
+// writeRepo appends a [[sites.repos]] table to a TOML builder using the given indentation
+func writeRepo(builder *strings.Builder, repo Repo, headerIndent, keyIndent string) {
+	builder.WriteString(headerIndent + "[[sites.repos]]\n")
+	builder.WriteString(fmt.Sprintf("%srepo = %q\n", keyIndent, repo.Repo))
+	
+	if repo.Rename != "" {
+		builder.WriteString(fmt.Sprintf("%srename = %q\n", keyIndent, repo.Rename))
+	}
+	
+	if repo.WarmUp {
+		builder.WriteString(keyIndent + "warm_up = true\n")
+	}
+	
+	if repo.Memo != "" {
+		builder.WriteString(fmt.Sprintf("%smemo = %q\n", keyIndent, repo.Memo))
+	}
+
+	if repo.Depth != nil {
+		builder.WriteString(fmt.Sprintf("%sdepth = %d\n", keyIndent, *repo.Depth))
+	}
+
+	if repo.Update != "" {
+		builder.WriteString(fmt.Sprintf("%supdate = %q\n", keyIndent, repo.Update))
+	}
+
+	if len(repo.Tags) > 0 {
+		builder.WriteString(fmt.Sprintf("%stags = %s\n", keyIndent, tomlStringArray(repo.Tags)))
+	}
+
+	if repo.Branch != nil {
+		builder.WriteString(fmt.Sprintf("%sbranch = %q\n", keyIndent, *repo.Branch))
+	}
+
+	if repo.Commit != "" {
+		builder.WriteString(fmt.Sprintf("%scommit = %q\n", keyIndent, repo.Commit))
+	}
+
+	if len(repo.WarmUpCommands) > 0 {
+		builder.WriteString(fmt.Sprintf("%swarm_up_commands = %s\n", keyIndent, tomlCommandArray(repo.WarmUpCommands)))
+	}
+
+	if len(repo.WarmUpSteps) > 0 {
+		builder.WriteString(fmt.Sprintf("%swarm_up_steps = %s\n", keyIndent, tomlWarmUpSteps(repo.WarmUpSteps)))
+	}
+
+	if repo.WarmUpMode != "" {
+		builder.WriteString(fmt.Sprintf("%swarm_up_mode = %q\n", keyIndent, repo.WarmUpMode))
+	}
+
+	if repo.WarmUpMutable != nil {
+		builder.WriteString(fmt.Sprintf("%swarm_up_mutable = %t\n", keyIndent, *repo.WarmUpMutable))
+	}
+
+	if !repo.Hooks.IsEmpty() {
+		builder.WriteString(fmt.Sprintf("%shooks = %s\n", keyIndent, tomlHooks(repo.Hooks)))
+	}
+
+	if repo.Submodules {
+		builder.WriteString(keyIndent + "submodules = true\n")
+	}
+
+	if len(repo.Worktrees) > 0 {
+		builder.WriteString(fmt.Sprintf("%sworktrees = %s\n", keyIndent, tomlWorktrees(repo.Worktrees)))
+	}
+}
+
+// writeSiteDefaults appends the [sites.defaults] table of a site to a TOML builder
+func writeSiteDefaults(builder *strings.Builder, defaults *SiteDefaults) {
+	if defaults == nil {
+		return
+	}
+	var body strings.Builder
+
+	if defaults.Branch != "" {
+		body.WriteString(fmt.Sprintf("        branch = %q\n", defaults.Branch))
+	}
+	if defaults.Depth > 0 {
+		body.WriteString(fmt.Sprintf("        depth = %d\n", defaults.Depth))
+	}
+	if defaults.Update != "" {
+		body.WriteString(fmt.Sprintf("        update = %q\n", defaults.Update))
+	}
+	if len(defaults.WarmUpCommands) > 0 {
+		body.WriteString(fmt.Sprintf("        warm_up_commands = %s\n", tomlCommandArray(defaults.WarmUpCommands)))
+	}
+	if len(defaults.WarmUpSteps) > 0 {
+		body.WriteString(fmt.Sprintf("        warm_up_steps = %s\n", tomlWarmUpSteps(defaults.WarmUpSteps)))
+	}
+	if defaults.WarmUpMode != "" {
+		body.WriteString(fmt.Sprintf("        warm_up_mode = %q\n", defaults.WarmUpMode))
+	}
+	if defaults.WarmUpMutable {
+		body.WriteString("        warm_up_mutable = true\n")
+	}
+	if len(defaults.Tags) > 0 {
+		body.WriteString(fmt.Sprintf("        tags = %s\n", tomlStringArray(defaults.Tags)))
+	}
+	if !defaults.Hooks.IsEmpty() {
+		body.WriteString(fmt.Sprintf("        hooks = %s\n", tomlHooks(defaults.Hooks)))
+	}
+
+	if body.Len() == 0 {
+		return
+	}
+
+	builder.WriteString("    [sites.defaults]\n")
+	builder.WriteString(body.String())
+	builder.WriteString("\n")
+}
+
+// tomlHooks formats hooks as an inline TOML table
+func tomlHooks(hooks *Hooks) string {
+	parts := make([]string, 0, 2)
+	if len(hooks.PostClone) > 0 {
+		parts = append(parts, "post_clone = "+tomlCommandArray(hooks.PostClone))
+	}
+	if len(hooks.PostUpdate) > 0 {
+		parts = append(parts, "post_update = "+tomlCommandArray(hooks.PostUpdate))
+	}
+	return "{ " + strings.Join(parts, ", ") + " }"
+}
+
+// tomlWorktrees formats worktrees as an inline TOML array of tables
+func tomlWorktrees(worktrees []Worktree) string {
+	formatted := make([]string, len(worktrees))
+	for i, worktree := range worktrees {
+		formatted[i] = fmt.Sprintf("{ path = %q", worktree.Path)
+		if worktree.Branch != "" {
+			formatted[i] += fmt.Sprintf(", branch = %q", worktree.Branch)
+		}
+		formatted[i] += " }"
+	}
+	return "[" + strings.Join(formatted, ", ") + "]"
+}
+
+// tomlWarmUpSteps formats warm-up steps as an inline TOML array of tables
+func tomlWarmUpSteps(steps []WarmUpStep) string {
+	formatted := make([]string, len(steps))
+	for i, step := range steps {
+		formatted[i] = "{ run = " + tomlStringArray(step.Run)
+		if step.Dir != "" {
+			formatted[i] += fmt.Sprintf(", dir = %q", step.Dir)
+		}
+		if len(step.Env) > 0 {
+			keys := make([]string, 0, len(step.Env))
+			for key := range step.Env {
+				keys = append(keys, key)
 			}
-			
-			if repo.Memo != "" {
-				builder.WriteString(fmt.Sprintf("        memo = %q\n", repo.Memo))
+			sort.Strings(keys)
+			env := make([]string, len(keys))
+			for j, key := range keys {
+				env[j] = fmt.Sprintf("%q = %q", key, step.Env[key])
 			}
-			
-			builder.WriteString("\n")
+			formatted[i] += ", env = { " + strings.Join(env, ", ") + " }"
+		}
+		if step.Timeout != "" {
+			formatted[i] += fmt.Sprintf(", timeout = %q", step.Timeout)
 		}
+		formatted[i] += " }"
 	}
-	
-	return builder.String(), nil
-} 
\ No newline at end of file
+	return "[" + strings.Join(formatted, ", ") + "]"
+}
+
+// tomlCommandArray formats a list of commands as a nested inline TOML array
+func tomlCommandArray(commands [][]string) string {
+	formatted := make([]string, len(commands))
+	for i, command := range commands {
+		formatted[i] = tomlStringArray(command)
+	}
+	return "[" + strings.Join(formatted, ", ") + "]"
+}
+
+// tomlStringArray formats a string slice as an inline TOML array
+func tomlStringArray(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
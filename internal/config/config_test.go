@@ -221,6 +221,15 @@ func TestRepo_RepoUrl_WithTrailingSlash(t *testing.T) {
 	}
 }
 
+func TestRepo_RepoUrl_SSHPrefix(t *testing.T) {
+	site := SiteConfig{RemotePrefix: "git@github.com:"}
+
+	// SSH 前缀与仓库路径之间不加斜杠
+	if result := (Repo{Repo: "user/repo"}).RepoUrl(site); result != "git@github.com:user/repo.git" {
+		t.Errorf("RepoUrl() = %s, expected git@github.com:user/repo.git", result)
+	}
+}
+
 func TestRepo_FullPath(t *testing.T) {
 	site := SiteConfig{
 		Dir: "/home/user/repos",
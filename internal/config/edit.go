@@ -0,0 +1,444 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/khicago/repoll/internal/git"
+)
+
+// tableHeaderPattern matches TOML table headers such as [[sites]] or [sites.defaults]
+var tableHeaderPattern = regexp.MustCompile(`^(\s*)(\[\[|\[)\s*([^\[\]]+?)\s*(\]\]|\])\s*(#.*)?$`)
+
+// RepoEdit describes an addition or removal of a repository in a configuration file
+type RepoEdit struct {
+	Site    SiteConfig
+	Repo    Repo
+	NewSite bool
+	// Content is the updated configuration file
+	Content []byte
+}
+
+// Write saves the edited configuration file
+func (e *RepoEdit) Write(configPath string) error {
+	return writeConfigContent(configPath, e.Content)
+}
+
+// writeConfigContent writes edited configuration data to a file, or to standard output for StdinPath
+func writeConfigContent(configPath string, content []byte) error {
+	if configPath == StdinPath {
+		_, err := stdout.Write(content)
+		return err
+	}
+	if err := os.WriteFile(configPath, content, 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// tomlHeader is a table header line of a TOML document
+type tomlHeader struct {
+	line   int
+	indent string
+	name   string
+}
+
+// AddRepo adds the repository at url to a configuration file, which is created when missing.
+// The repository joins the site with the longest matching remote prefix; a new site using
+// siteDir is created when none matches. TOML files are edited in place so comments and
+// ordering are preserved.
+func AddRepo(configPath, url string, repo Repo, siteDir string) (*RepoEdit, error) {
+	if !hasRepoPath(url) {
+		return nil, fmt.Errorf("not a repository URL: %s (expected an owner/repository path)", url)
+	}
+
+	data, format, err := readConfigData(configPath)
+	cfg := &Config{}
+	switch {
+	case err != nil && configPath != StdinPath && errors.Is(err, fs.ErrNotExist):
+		data, format = nil, FormatFromPath(configPath)
+	case err != nil:
+		return nil, err
+	default:
+		if cfg, err = Parse(data, format); err != nil {
+			return nil, err
+		}
+	}
+
+	siteIndex, repoPath := cfg.FindSiteForURL(url)
+	if repoPath == "" {
+		return nil, fmt.Errorf("could not parse repository URL: %s", url)
+	}
+	repo.Repo = repoPath
+
+	edit := &RepoEdit{Repo: repo}
+	if siteIndex >= 0 {
+		for _, existing := range cfg.Sites[siteIndex].Repos {
+			if existing.Repo == repo.Repo {
+				return nil, fmt.Errorf("repository %s already exists in site %s", repo.Repo, cfg.Sites[siteIndex].RemotePrefix)
+			}
+		}
+		edit.Site = cfg.Sites[siteIndex]
+	} else {
+		edit.NewSite = true
+		edit.Site = SiteConfig{
+			RemotePrefix: sitePrefixForURL(url, repoPath),
+			Dir:          siteDir,
+		}
+	}
+
+	if format != FormatTOML {
+		if edit.NewSite {
+			site := edit.Site
+			site.Repos = []Repo{repo}
+			cfg.Sites = append(cfg.Sites, site)
+		} else {
+			cfg.Sites[siteIndex].Repos = append(cfg.Sites[siteIndex].Repos, repo)
+		}
+		edit.Content, err = Marshal(cfg, format)
+		return edit, err
+	}
+
+	if edit.NewSite {
+		siteIndex = -1
+	}
+	edit.Content, err = insertRepoTOML(data, cfg, siteIndex, edit.Site, repo)
+	if err != nil {
+		return nil, err
+	}
+	return edit, nil
+}
+
+// insertRepoTOML appends repo to the site at siteIndex of a TOML document, matching the
+// indentation of its existing tables. A siteIndex of -1 inserts site as a new [[sites]]
+// table after the last site.
+func insertRepoTOML(data []byte, cfg *Config, siteIndex int, site SiteConfig, repo Repo) ([]byte, error) {
+	lines := splitLines(string(data))
+	headers := parseTOMLHeaders(lines)
+	sites := siteHeaders(headers)
+	if len(sites) != len(cfg.Sites) {
+		return nil, fmt.Errorf("unsupported layout: expected %d [[sites]] tables, found %d", len(cfg.Sites), len(sites))
+	}
+
+	var builder strings.Builder
+	var insertAt int
+	if siteIndex < 0 {
+		// The new site mirrors the indentation of the last site, or the ToTOML layout
+		insertAt = len(lines)
+		siteIndent, siteKeyIndent, headerIndent, keyIndent := "", "    ", "    ", "        "
+		if len(sites) > 0 {
+			last := sites[len(sites)-1]
+			start, end := siteBlock(lines, headers, last)
+			insertAt = trimBlockEnd(lines, start, end)
+			siteIndent, siteKeyIndent = last.indent, siteKeyIndentation(lines, last, end)
+			headerIndent, keyIndent = repoIndentation(lines, headers, last, start, end)
+		}
+		builder.WriteString(siteIndent + "[[sites]]\n")
+		builder.WriteString(fmt.Sprintf("%sremote = %q\n", siteKeyIndent, site.RemotePrefix))
+		builder.WriteString(fmt.Sprintf("%sdir = %q\n\n", siteKeyIndent, site.Dir))
+		writeRepo(&builder, repo, headerIndent, keyIndent)
+	} else {
+		header := sites[siteIndex]
+		start, end := siteBlock(lines, headers, header)
+		insertAt = trimBlockEnd(lines, start, end)
+		headerIndent, keyIndent := repoIndentation(lines, headers, header, start, end)
+		writeRepo(&builder, repo, headerIndent, keyIndent)
+	}
+
+	block := splitLines(strings.TrimRight(builder.String(), "\n"))
+	if insertAt > 0 {
+		block = append([]string{""}, block...)
+	}
+	if insertAt < len(lines) && strings.TrimSpace(lines[insertAt]) != "" {
+		block = append(block, "")
+	}
+	lines = append(lines[:insertAt], append(block, lines[insertAt:]...)...)
+
+	return []byte(joinLines(lines)), nil
+}
+
+// RemoveRepo removes the repository matching name from a configuration file.
+// The name may be the repository path, its rename or its last path component.
+func RemoveRepo(configPath, name string) (*RepoEdit, error) {
+	data, format, err := readConfigData(configPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := Parse(data, format)
+	if err != nil {
+		return nil, err
+	}
+
+	siteIndex, repoIndex, err := cfg.FindRepo(name)
+	if err != nil {
+		return nil, err
+	}
+
+	edit := &RepoEdit{
+		Site: cfg.Sites[siteIndex],
+		Repo: cfg.Sites[siteIndex].Repos[repoIndex],
+	}
+
+	if format != FormatTOML {
+		repos := cfg.Sites[siteIndex].Repos
+		cfg.Sites[siteIndex].Repos = append(repos[:repoIndex:repoIndex], repos[repoIndex+1:]...)
+		edit.Content, err = Marshal(cfg, format)
+		return edit, err
+	}
+
+	lines := splitLines(string(data))
+	headers := parseTOMLHeaders(lines)
+	sites := siteHeaders(headers)
+	if len(sites) != len(cfg.Sites) {
+		return nil, fmt.Errorf("unsupported layout: expected %d [[sites]] tables, found %d", len(cfg.Sites), len(sites))
+	}
+
+	siteStart, siteEnd := siteBlock(lines, headers, sites[siteIndex])
+	var repoHeaders []tomlHeader
+	for _, header := range headers {
+		if header.name == "[[sites.repos]]" && header.line > siteStart && header.line < siteEnd {
+			repoHeaders = append(repoHeaders, header)
+		}
+	}
+	if len(repoHeaders) != len(cfg.Sites[siteIndex].Repos) {
+		return nil, fmt.Errorf("unsupported layout: expected %d [[sites.repos]] tables, found %d", len(cfg.Sites[siteIndex].Repos), len(repoHeaders))
+	}
+
+	// The repository ends at the first header that is not one of its sub-tables
+	start := repoHeaders[repoIndex].line
+	end := siteEnd
+	for _, header := range headers {
+		if header.line > start && !isRepoSubTable(header.name) {
+			end = min(end, header.line)
+			break
+		}
+	}
+	end = trimBlockEnd(lines, start, end)
+
+	// Remove comments attached directly above the table
+	for start > 0 && strings.HasPrefix(strings.TrimSpace(lines[start-1]), "#") {
+		start--
+	}
+	// Drop the blank line separating the table from its predecessor
+	if start > 0 && strings.TrimSpace(lines[start-1]) == "" && (end >= len(lines) || strings.TrimSpace(lines[end]) == "") {
+		start--
+	}
+
+	lines = append(lines[:start], lines[end:]...)
+	edit.Content = []byte(joinLines(lines))
+	return edit, nil
+}
+
+// FindSiteForURL returns the index of the site whose remote prefix matches url and the
+// repository path relative to it. The index is -1 when no site matches; the path is then
+// derived from the URL alone.
+func (cfg *Config) FindSiteForURL(url string) (int, string) {
+	url = strings.TrimSuffix(strings.TrimSpace(url), ".git")
+
+	best, bestLen, repoPath := -1, 0, ""
+	for i, site := range cfg.Sites {
+		prefix := strings.TrimSuffix(site.RemotePrefix, "/")
+		if prefix == "" || len(prefix) <= bestLen || !strings.HasPrefix(url, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(url, prefix)
+		// The prefix must end at a path boundary, so "github.com/org" does not match "github.com/organization"
+		if !strings.HasSuffix(prefix, ":") && !strings.HasPrefix(rest, "/") && !strings.HasPrefix(rest, ":") {
+			continue
+		}
+		rest = strings.TrimLeft(rest, "/:")
+		if rest == "" {
+			continue
+		}
+		best, bestLen, repoPath = i, len(prefix), rest
+	}
+	if best >= 0 {
+		return best, repoPath
+	}
+
+	// Match SSH URLs against sites using the equivalent HTTPS prefix
+	prefix := git.ExtractRemotePrefix(url)
+	name := git.ExtractRepoNameFromURL(url)
+	for i, site := range cfg.Sites {
+		if prefix != "" && strings.TrimSuffix(site.RemotePrefix, "/") == strings.TrimSuffix(prefix, "/") {
+			return i, name
+		}
+	}
+	return -1, name
+}
+
+// FindRepo locates a repository by its path, rename or last path component
+func (cfg *Config) FindRepo(name string) (int, int, error) {
+	type match struct{ site, repo int }
+	var matches []match
+
+	for i, site := range cfg.Sites {
+		for j, repo := range site.Repos {
+			if repo.Repo == name || repo.Rename == name || path.Base(repo.Repo) == name {
+				matches = append(matches, match{i, j})
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return -1, -1, fmt.Errorf("repository %s not found", name)
+	case 1:
+		return matches[0].site, matches[0].repo, nil
+	default:
+		return -1, -1, fmt.Errorf("repository name %s is ambiguous (%d matches), use the full path", name, len(matches))
+	}
+}
+
+// parseTOMLHeaders returns the table headers of a TOML document in order
+func parseTOMLHeaders(lines []string) []tomlHeader {
+	var headers []tomlHeader
+	for i, line := range lines {
+		m := tableHeaderPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		name := strings.Join(strings.Fields(m[3]), "")
+		if m[2] == "[[" {
+			name = "[[" + name + "]]"
+		} else {
+			name = "[" + name + "]"
+		}
+		headers = append(headers, tomlHeader{line: i, indent: m[1], name: name})
+	}
+	return headers
+}
+
+// isRepoSubTable reports whether a header opens a table nested in a repository, such as
+// [sites.repos.hooks] or [[sites.repos.worktrees]]
+func isRepoSubTable(name string) bool {
+	return strings.HasPrefix(strings.TrimLeft(name, "["), "sites.repos.")
+}
+
+// siteHeaders filters the [[sites]] headers
+func siteHeaders(headers []tomlHeader) []tomlHeader {
+	var sites []tomlHeader
+	for _, header := range headers {
+		if header.name == "[[sites]]" {
+			sites = append(sites, header)
+		}
+	}
+	return sites
+}
+
+// siteBlock returns the line range of a site, up to the next table outside the site
+func siteBlock(lines []string, headers []tomlHeader, site tomlHeader) (int, int) {
+	end := len(lines)
+	for _, header := range headers {
+		if header.line <= site.line {
+			continue
+		}
+		if !strings.HasPrefix(header.name, "[sites.") && !strings.HasPrefix(header.name, "[[sites.") {
+			end = header.line
+			break
+		}
+	}
+	return site.line, end
+}
+
+// trimBlockEnd moves the end of a block before trailing blank lines and comments,
+// which belong to whatever follows the block
+func trimBlockEnd(lines []string, start, end int) int {
+	for end-1 > start {
+		trimmed := strings.TrimSpace(lines[end-1])
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			break
+		}
+		end--
+	}
+	return end
+}
+
+// repoIndentation mirrors the indentation of existing repository tables in a site
+func repoIndentation(lines []string, headers []tomlHeader, site tomlHeader, start, end int) (string, string) {
+	for _, header := range headers {
+		if header.name != "[[sites.repos]]" || header.line <= start || header.line >= end {
+			continue
+		}
+		if header.line+1 < end {
+			if key := leadingWhitespace(lines[header.line+1]); strings.TrimSpace(lines[header.line+1]) != "" {
+				return header.indent, key
+			}
+		}
+		return header.indent, header.indent
+	}
+
+	// No repositories yet: indent one level deeper than the site keys
+	siteKeyIndent := siteKeyIndentation(lines, site, end)
+	step := strings.TrimPrefix(siteKeyIndent, site.indent)
+	return siteKeyIndent, siteKeyIndent + step
+}
+
+// siteKeyIndentation returns the indentation of the keys of a site, taken from the line after its header
+func siteKeyIndentation(lines []string, site tomlHeader, end int) string {
+	if site.line+1 < end && strings.TrimSpace(lines[site.line+1]) != "" {
+		return leadingWhitespace(lines[site.line+1])
+	}
+	return site.indent
+}
+
+// hasRepoPath reports whether a repository URL names at least an owner and a repository,
+// as in https://host/owner/repo or git@host:owner/repo
+func hasRepoPath(url string) bool {
+	url = strings.TrimSuffix(strings.TrimSpace(url), ".git")
+	if i := strings.Index(url, "://"); i >= 0 {
+		// Skip the scheme and the host
+		url = url[i+3:]
+		if j := strings.Index(url, "/"); j >= 0 {
+			url = url[j+1:]
+		} else {
+			url = ""
+		}
+	} else if i := strings.Index(url, ":"); i >= 0 {
+		url = url[i+1:]
+	}
+
+	segments := 0
+	for _, segment := range strings.Split(url, "/") {
+		if segment != "" {
+			segments++
+		}
+	}
+	return segments >= 2
+}
+
+// sitePrefixForURL returns the remote prefix of a new site for a repository URL, keeping the
+// URL's transport: git@host:owner/repo gives git@host: rather than the HTTPS equivalent
+func sitePrefixForURL(url, repoPath string) string {
+	trimmed := strings.TrimSuffix(strings.TrimSpace(url), ".git")
+	if !strings.Contains(trimmed, "://") && strings.HasSuffix(trimmed, ":"+repoPath) {
+		return strings.TrimSuffix(trimmed, repoPath)
+	}
+	return remotePrefixFor(url, repoPath)
+}
+
+// leadingWhitespace returns the indentation of a line
+func leadingWhitespace(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// splitLines splits a document into lines without the trailing newline
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// joinLines joins lines into a document ending with a newline
+func joinLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,371 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const editConfigContent = `# GitHub repositories
+[[sites]]
+    remote = "https://github.com/"
+    dir = "./github/"
+
+    # the go toolchain
+    [[sites.repos]]
+        repo = "golang/go"
+        memo = "Go language"  # keep me
+
+    [[sites.repos]]
+        repo = "rust-lang/rust"
+
+# Company repositories
+[[sites]]
+  remote = "git@company.com:"
+  dir = "./company/"
+
+[profiles.ci]
+    depth = 1
+`
+
+func writeEditConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	configFile := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test config: %v", err)
+	}
+	return configFile
+}
+
+func TestAddRepo_ExistingSite(t *testing.T) {
+	configFile := writeEditConfig(t, "repos.toml", editConfigContent)
+
+	edit, err := AddRepo(configFile, "https://github.com/khicago/repoll.git", Repo{Rename: "rp", Tags: []string{"tools"}}, "./")
+	if err != nil {
+		t.Fatalf("AddRepo failed: %v", err)
+	}
+	if edit.NewSite || edit.Repo.Repo != "khicago/repoll" {
+		t.Errorf("Unexpected edit: %+v", edit)
+	}
+
+	content := string(edit.Content)
+	// 注释和原有顺序应保持不变，新仓库沿用已有缩进追加在站点末尾
+	expected := `    [[sites.repos]]
+        repo = "rust-lang/rust"
+
+    [[sites.repos]]
+        repo = "khicago/repoll"
+        rename = "rp"
+        tags = ["tools"]
+
+# Company repositories
+`
+	if !strings.Contains(content, expected) {
+		t.Errorf("Repository not appended to the site:\n%s", content)
+	}
+	for _, line := range []string{"# GitHub repositories", "# the go toolchain", `memo = "Go language"  # keep me`} {
+		if !strings.Contains(content, line) {
+			t.Errorf("Expected %q to be preserved", line)
+		}
+	}
+
+	cfg, err := Parse(edit.Content, FormatTOML)
+	if err != nil {
+		t.Fatalf("Edited config does not parse: %v", err)
+	}
+	if got := repoNames(cfg.Sites[0].Repos); strings.Join(got, ",") != "golang/go,rust-lang/rust,khicago/repoll" {
+		t.Errorf("Unexpected repos: %v", got)
+	}
+}
+
+func TestAddRepo_SiteWithoutRepos(t *testing.T) {
+	configFile := writeEditConfig(t, "repos.toml", editConfigContent)
+
+	edit, err := AddRepo(configFile, "git@company.com:team/api.git", Repo{}, "./")
+	if err != nil {
+		t.Fatalf("AddRepo failed: %v", err)
+	}
+
+	// 站点没有仓库时按站点键的缩进推导
+	expected := `  dir = "./company/"
+
+  [[sites.repos]]
+    repo = "team/api"
+
+[profiles.ci]`
+	if !strings.Contains(string(edit.Content), expected) {
+		t.Errorf("Unexpected content:\n%s", edit.Content)
+	}
+}
+
+func TestAddRepo_NewSite(t *testing.T) {
+	configFile := writeEditConfig(t, "repos.toml", editConfigContent)
+
+	edit, err := AddRepo(configFile, "https://gitlab.com/group/project", Repo{}, "./gitlab/")
+	if err != nil {
+		t.Fatalf("AddRepo failed: %v", err)
+	}
+	if !edit.NewSite || edit.Site.RemotePrefix != "https://gitlab.com/" || edit.Site.Dir != "./gitlab/" {
+		t.Errorf("Unexpected site: %+v", edit.Site)
+	}
+
+	// 新站点插入在最后一个站点之后、profiles 之前
+	content := string(edit.Content)
+	siteIndex := strings.Index(content, `remote = "https://gitlab.com/"`)
+	if siteIndex < 0 || siteIndex > strings.Index(content, "[profiles.ci]") {
+		t.Errorf("New site not inserted before profiles:\n%s", content)
+	}
+
+	cfg, err := Parse(edit.Content, FormatTOML)
+	if err != nil {
+		t.Fatalf("Edited config does not parse: %v", err)
+	}
+	if len(cfg.Sites) != 3 || cfg.Sites[2].Repos[0].Repo != "group/project" || *cfg.Profiles["ci"].Depth != 1 {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+}
+
+func TestAddRepo_NewSiteIndentation(t *testing.T) {
+	configFile := writeEditConfig(t, "repos.toml", `[[sites]]
+  remote = "https://github.com/"
+  dir = "./github/"
+
+  [[sites.repos]]
+    repo = "golang/go"
+`)
+
+	edit, err := AddRepo(configFile, "https://gitlab.com/group/project", Repo{}, "./gitlab/")
+	if err != nil {
+		t.Fatalf("AddRepo failed: %v", err)
+	}
+
+	// 新站点沿用文件中已有的缩进
+	expected := `    repo = "golang/go"
+
+[[sites]]
+  remote = "https://gitlab.com/"
+  dir = "./gitlab/"
+
+  [[sites.repos]]
+    repo = "group/project"
+`
+	if !strings.HasSuffix(string(edit.Content), expected) {
+		t.Errorf("Unexpected content:\n%s", edit.Content)
+	}
+}
+
+func TestAddRepo_NewSiteKeepsSSH(t *testing.T) {
+	configFile := writeEditConfig(t, "repos.toml", editConfigContent)
+
+	edit, err := AddRepo(configFile, "git@gitlab.com:group/project.git", Repo{}, "./gitlab/")
+	if err != nil {
+		t.Fatalf("AddRepo failed: %v", err)
+	}
+
+	// SSH 地址创建的站点保留 SSH 前缀，克隆地址与输入一致
+	if !edit.NewSite || edit.Site.RemotePrefix != "git@gitlab.com:" || edit.Repo.Repo != "group/project" {
+		t.Errorf("Unexpected edit: %+v", edit)
+	}
+	if url := edit.Repo.RepoUrl(edit.Site); url != "git@gitlab.com:group/project.git" {
+		t.Errorf("Expected SSH clone URL, got %s", url)
+	}
+}
+
+func TestAddRepo_MissingFile(t *testing.T) {
+	for _, name := range []string{"repos.toml", "repos.yaml", "repos.json"} {
+		t.Run(name, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), name)
+
+			// 配置文件不存在时创建新文件
+			edit, err := AddRepo(configFile, "https://github.com/golang/go.git", Repo{}, "./")
+			if err != nil {
+				t.Fatalf("AddRepo failed: %v", err)
+			}
+			if err := edit.Write(configFile); err != nil {
+				t.Fatalf("Write failed: %v", err)
+			}
+
+			cfg, err := ReadFromFile(configFile)
+			if err != nil {
+				t.Fatalf("Created config does not parse: %v", err)
+			}
+			if len(cfg.Sites) != 1 || cfg.Sites[0].RemotePrefix != "https://github.com/" || repoNames(cfg.Sites[0].Repos)[0] != "golang/go" {
+				t.Errorf("Unexpected config: %+v", cfg)
+			}
+		})
+	}
+}
+
+func TestAddRepo_NotARepository(t *testing.T) {
+	configFile := writeEditConfig(t, "repos.toml", editConfigContent)
+
+	// 只有组织、没有仓库路径的地址被拒绝
+	for _, url := range []string{"https://github.com/org", "https://github.com/", "git@github.com:org"} {
+		if _, err := AddRepo(configFile, url, Repo{}, "./"); err == nil || !strings.Contains(err.Error(), "not a repository URL") {
+			t.Errorf("Expected %s to be rejected, got %v", url, err)
+		}
+	}
+}
+
+func TestAddRepo_Duplicate(t *testing.T) {
+	configFile := writeEditConfig(t, "repos.toml", editConfigContent)
+
+	if _, err := AddRepo(configFile, "git@github.com:golang/go.git", Repo{}, "./"); err == nil {
+		t.Error("Expected error for duplicate repository")
+	}
+}
+
+func TestAddRepo_YAML(t *testing.T) {
+	configFile := writeEditConfig(t, "repos.yaml", yamlConfigContent)
+
+	edit, err := AddRepo(configFile, "https://github.com/golang/tools", Repo{}, "./")
+	if err != nil {
+		t.Fatalf("AddRepo failed: %v", err)
+	}
+	cfg, err := Parse(edit.Content, FormatYAML)
+	if err != nil {
+		t.Fatalf("Edited config does not parse: %v", err)
+	}
+	if got := repoNames(cfg.Sites[0].Repos); strings.Join(got, ",") != "golang/example,golang/tools" {
+		t.Errorf("Unexpected repos: %v", got)
+	}
+}
+
+func TestRemoveRepo(t *testing.T) {
+	configFile := writeEditConfig(t, "repos.toml", editConfigContent)
+
+	edit, err := RemoveRepo(configFile, "go")
+	if err != nil {
+		t.Fatalf("RemoveRepo failed: %v", err)
+	}
+	if edit.Repo.Repo != "golang/go" || edit.Repo.FullPath(edit.Site) != filepath.Join("github", "go") {
+		t.Errorf("Unexpected edit: %+v", edit)
+	}
+
+	// 仓库连同其上方的注释一起删除，其余内容不变
+	expected := `# GitHub repositories
+[[sites]]
+    remote = "https://github.com/"
+    dir = "./github/"
+
+    [[sites.repos]]
+        repo = "rust-lang/rust"
+
+# Company repositories
+`
+	if !strings.HasPrefix(string(edit.Content), expected) {
+		t.Errorf("Unexpected content:\n%s", edit.Content)
+	}
+	if err := edit.Write(configFile); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if _, err := ReadFromFile(configFile); err != nil {
+		t.Errorf("Edited config does not parse: %v", err)
+	}
+}
+
+func TestRemoveRepo_SubTables(t *testing.T) {
+	content := `[[sites]]
+    remote = "https://github.com/"
+    dir = "./"
+
+    [[sites.repos]]
+        repo = "team/a"
+
+        [sites.repos.hooks]
+            post_clone = [["make", "setup"]]
+
+    [[sites.repos]]
+        repo = "team/b"
+
+        [sites.repos.hooks]
+            post_clone = [["make"]]
+
+        [[sites.repos.worktrees]]
+            branch = "release"
+
+[profiles.ci]
+    depth = 1
+`
+	configFile := writeEditConfig(t, "repos.toml", content)
+
+	// 仓库的子表与仓库一起删除，不会归到前一个仓库下
+	edit, err := RemoveRepo(configFile, "b")
+	if err != nil {
+		t.Fatalf("RemoveRepo failed: %v", err)
+	}
+	expected := `[[sites]]
+    remote = "https://github.com/"
+    dir = "./"
+
+    [[sites.repos]]
+        repo = "team/a"
+
+        [sites.repos.hooks]
+            post_clone = [["make", "setup"]]
+
+[profiles.ci]
+    depth = 1
+`
+	if string(edit.Content) != expected {
+		t.Errorf("Unexpected content:\n%s", edit.Content)
+	}
+	cfg, err := Parse(edit.Content, FormatTOML)
+	if err != nil {
+		t.Fatalf("Edited config does not parse: %v", err)
+	}
+	if repos := cfg.Sites[0].Repos; len(repos) != 1 || len(repos[0].Hooks.PostClone) != 1 || *cfg.Profiles["ci"].Depth != 1 {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+}
+
+func TestRemoveRepo_NotFoundOrAmbiguous(t *testing.T) {
+	content := editConfigContent + `
+[[sites]]
+    remote = "https://gitlab.com/"
+    dir = "./gitlab/"
+
+    [[sites.repos]]
+        repo = "mirror/go"
+`
+	configFile := writeEditConfig(t, "repos.toml", content)
+
+	if _, err := RemoveRepo(configFile, "missing"); err == nil {
+		t.Error("Expected error for unknown repository")
+	}
+	if _, err := RemoveRepo(configFile, "go"); err == nil {
+		t.Error("Expected error for ambiguous repository name")
+	}
+	if _, err := RemoveRepo(configFile, "mirror/go"); err != nil {
+		t.Errorf("Expected full path to resolve the ambiguity: %v", err)
+	}
+}
+
+func TestFindSiteForURL(t *testing.T) {
+	cfg := &Config{Sites: []SiteConfig{
+		{RemotePrefix: "https://github.com/"},
+		{RemotePrefix: "https://github.com/company/"},
+		{RemotePrefix: "git@company.com:"},
+	}}
+
+	tests := []struct {
+		url      string
+		site     int
+		repoPath string
+	}{
+		{"https://github.com/golang/go.git", 0, "golang/go"},
+		{"https://github.com/company/api", 1, "api"},
+		{"https://github.com/companyx/api", 0, "companyx/api"},
+		{"git@company.com:team/api.git", 2, "team/api"},
+		{"git@github.com:golang/go.git", 0, "golang/go"},
+		{"https://gitlab.com/group/project", -1, "group/project"},
+	}
+
+	for _, tt := range tests {
+		site, repoPath := cfg.FindSiteForURL(tt.url)
+		if site != tt.site || repoPath != tt.repoPath {
+			t.Errorf("FindSiteForURL(%q) = %d, %q, want %d, %q", tt.url, site, repoPath, tt.site, tt.repoPath)
+		}
+	}
+}
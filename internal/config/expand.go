@@ -0,0 +1,189 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/khicago/repoll/internal/git"
+)
+
+// maxMirrorDepth bounds how deep a mirror directory is searched for bare repositories
+const maxMirrorDepth = 4
+
+// Expand describes how a site's repository list is generated from an external source
+type Expand struct {
+	// From is a provider listing file (JSON) or a local directory of bare mirrors.
+	// Relative paths are resolved against the configuration file's directory.
+	From string `toml:"from" yaml:"from" json:"from" desc:"JSON listing file or directory of bare mirrors" jsonschema:"required"`
+	// RepoGlob filters expanded repositories by name, e.g. "team/*-service"
+	RepoGlob string `toml:"repo_glob" yaml:"repo_glob,omitempty" json:"repo_glob,omitempty" desc:"Glob filtering expanded repository names, e.g. team/*-service"`
+}
+
+// ExpandResult records the repositories added to a site by expansion
+type ExpandResult struct {
+	Site   string
+	Source string
+	Repos  []string
+}
+
+// listingEntry is a repository entry of a provider listing file.
+// Field names follow the GitHub and GitLab project APIs.
+type listingEntry struct {
+	FullName          string `json:"full_name"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	Name              string `json:"name"`
+	Description       string `json:"description"`
+}
+
+// ExpandSites replaces every site's expansion rule with the concrete repositories it matches.
+// Explicitly configured repositories take precedence over expanded ones with the same name.
+func (cfg *Config) ExpandSites(baseDir string) ([]ExpandResult, error) {
+	var results []ExpandResult
+
+	for i := range cfg.Sites {
+		site := &cfg.Sites[i]
+		if site.Expand == nil {
+			continue
+		}
+
+		source := site.Expand.From
+		if source == "" {
+			return nil, fmt.Errorf("site %s: expand requires a 'from' source", site.RemotePrefix)
+		}
+		if !filepath.IsAbs(source) {
+			source = filepath.Join(baseDir, source)
+		}
+
+		expanded, err := expandSource(source)
+		if err != nil {
+			return nil, fmt.Errorf("site %s: failed to expand %s: %w", site.RemotePrefix, site.Expand.From, err)
+		}
+
+		configured := make(map[string]bool, len(site.Repos))
+		for _, repo := range site.Repos {
+			configured[repo.Repo] = true
+		}
+
+		result := ExpandResult{Site: site.RemotePrefix, Source: site.Expand.From}
+		for _, repo := range expanded {
+			if site.Expand.RepoGlob != "" {
+				matched, err := path.Match(site.Expand.RepoGlob, repo.Repo)
+				if err != nil {
+					return nil, fmt.Errorf("site %s: invalid repo_glob %q: %w", site.RemotePrefix, site.Expand.RepoGlob, err)
+				}
+				if !matched {
+					continue
+				}
+			}
+			if configured[repo.Repo] {
+				continue
+			}
+			configured[repo.Repo] = true
+			site.Repos = append(site.Repos, repo)
+			result.Repos = append(result.Repos, repo.Repo)
+		}
+
+		site.Expand = nil
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// expandSource lists the repositories of a listing file or mirror directory
+func expandSource(source string) ([]Repo, error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return expandMirrorDir(source)
+	}
+	return expandListingFile(source)
+}
+
+// expandListingFile reads a JSON array of repository names or provider API objects
+func expandListingFile(filename string) ([]Repo, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse listing: %w", err)
+	}
+
+	repos := make([]Repo, 0, len(raw))
+	for _, item := range raw {
+		var name string
+		if err := json.Unmarshal(item, &name); err == nil {
+			repos = append(repos, Repo{Repo: name})
+			continue
+		}
+
+		var entry listingEntry
+		if err := json.Unmarshal(item, &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse listing entry %s: %w", string(item), err)
+		}
+		switch {
+		case entry.FullName != "":
+			name = entry.FullName
+		case entry.PathWithNamespace != "":
+			name = entry.PathWithNamespace
+		default:
+			name = entry.Name
+		}
+		if name == "" {
+			return nil, fmt.Errorf("listing entry without a repository name: %s", string(item))
+		}
+		repos = append(repos, Repo{Repo: name, Memo: entry.Description})
+	}
+
+	return repos, nil
+}
+
+// expandMirrorDir finds bare repositories below a mirror directory
+func expandMirrorDir(root string) ([]Repo, error) {
+	var names []string
+
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() || p == root {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if git.IsBareRepository(p) {
+			names = append(names, strings.TrimSuffix(rel, ".git"))
+			return filepath.SkipDir
+		}
+		if strings.Count(rel, "/")+1 >= maxMirrorDepth {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(names)
+	repos := make([]Repo, len(names))
+	for i, name := range names {
+		repos[i] = Repo{Repo: name}
+	}
+	return repos, nil
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func repoNames(repos []Repo) []string {
+	names := make([]string, len(repos))
+	for i, repo := range repos {
+		names[i] = repo.Repo
+	}
+	return names
+}
+
+func TestExpandSites_ListingFile(t *testing.T) {
+	baseDir := t.TempDir()
+	listing := `[
+  "team/user-service",
+  {"full_name": "team/payment-service", "description": "Payments"},
+  {"path_with_namespace": "team/web-frontend"},
+  {"name": "team/order-service"}
+]`
+	if err := os.WriteFile(filepath.Join(baseDir, "index.json"), []byte(listing), 0644); err != nil {
+		t.Fatalf("Failed to write listing: %v", err)
+	}
+
+	cfg := &Config{
+		Sites: []SiteConfig{
+			{
+				RemotePrefix: "https://git.company.com/",
+				Dir:          "./services/",
+				Expand:       &Expand{From: "index.json", RepoGlob: "team/*-service"},
+				Repos:        []Repo{{Repo: "team/user-service", Rename: "users"}},
+			},
+		},
+	}
+
+	results, err := cfg.ExpandSites(baseDir)
+	if err != nil {
+		t.Fatalf("ExpandSites failed: %v", err)
+	}
+
+	site := cfg.Sites[0]
+	expected := []string{"team/user-service", "team/payment-service", "team/order-service"}
+	if got := repoNames(site.Repos); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected repos %v, got %v", expected, got)
+	}
+
+	// 显式配置的仓库优先，不会被展开结果覆盖
+	if site.Repos[0].Rename != "users" {
+		t.Errorf("Expected explicit repo settings to be kept, got %+v", site.Repos[0])
+	}
+	if site.Repos[1].Memo != "Payments" {
+		t.Errorf("Expected description to become memo, got %q", site.Repos[1].Memo)
+	}
+	if site.Expand != nil {
+		t.Error("Expected expansion rule to be consumed")
+	}
+
+	if len(results) != 1 || !reflect.DeepEqual(results[0].Repos, []string{"team/payment-service", "team/order-service"}) {
+		t.Errorf("Unexpected expansion results: %+v", results)
+	}
+}
+
+func TestExpandSites_MirrorDirectory(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("Git not available, skipping test")
+	}
+
+	mirrorDir := t.TempDir()
+	for _, name := range []string{"team/api.git", "team/web.git", "other/tool.git"} {
+		cmd := exec.Command("git", "init", "--bare", filepath.Join(mirrorDir, name))
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git init --bare failed: %v\n%s", err, output)
+		}
+	}
+
+	cfg := &Config{
+		Sites: []SiteConfig{
+			{
+				RemotePrefix: "https://github.com/",
+				Dir:          "./mirrors/",
+				Expand:       &Expand{From: mirrorDir, RepoGlob: "team/*"},
+			},
+		},
+	}
+
+	if _, err := cfg.ExpandSites(""); err != nil {
+		t.Fatalf("ExpandSites failed: %v", err)
+	}
+
+	expected := []string{"team/api", "team/web"}
+	if got := repoNames(cfg.Sites[0].Repos); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected repos %v, got %v", expected, got)
+	}
+}
+
+func TestExpandSites_Errors(t *testing.T) {
+	baseDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(baseDir, "bad.json"), []byte(`{"not": "a list"}`), 0644); err != nil {
+		t.Fatalf("Failed to write listing: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(baseDir, "ok.json"), []byte(`["team/a"]`), 0644); err != nil {
+		t.Fatalf("Failed to write listing: %v", err)
+	}
+
+	tests := map[string]*Expand{
+		"missing from":    {},
+		"missing source":  {From: "missing.json"},
+		"invalid listing": {From: "bad.json"},
+		"invalid glob":    {From: "ok.json", RepoGlob: "team/["},
+	}
+
+	for name, expand := range tests {
+		cfg := &Config{Sites: []SiteConfig{{RemotePrefix: "https://github.com/", Expand: expand}}}
+		if _, err := cfg.ExpandSites(baseDir); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestReadFromFile_Expand(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "expand.toml")
+	configContent := `[[sites]]
+remote = "https://github.com/"
+dir = "./repos/"
+expand = { from = "index.json", repo_glob = "team/*" }
+`
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config: %v", err)
+	}
+
+	cfg, err := ReadFromFile(configFile)
+	if err != nil {
+		t.Fatalf("ReadFromFile failed: %v", err)
+	}
+
+	expand := cfg.Sites[0].Expand
+	if expand == nil || expand.From != "index.json" || expand.RepoGlob != "team/*" {
+		t.Errorf("Unexpected expand: %+v", expand)
+	}
+
+	content, err := ToTOML(cfg)
+	if err != nil {
+		t.Fatalf("ToTOML failed: %v", err)
+	}
+	if !strings.Contains(content, `expand = { from = "index.json", repo_glob = "team/*" }`) {
+		t.Errorf("Expected expand rule in TOML, got:\n%s", content)
+	}
+}
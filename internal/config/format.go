@@ -0,0 +1,125 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Supported configuration file formats
+const (
+	FormatTOML = "toml"
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+// StdinPath is the configuration path that reads from standard input (or writes to standard output)
+const StdinPath = "-"
+
+// stdin and stdout are replaceable for tests
+var (
+	stdin  io.Reader = os.Stdin
+	stdout io.Writer = os.Stdout
+)
+
+// FormatFromPath returns the configuration format for a file path based on its extension.
+// Unknown extensions fall back to TOML.
+func FormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".json":
+		return FormatJSON
+	default:
+		return FormatTOML
+	}
+}
+
+// IsConfigFile reports whether a path looks like a configuration file repoll can read
+func IsConfigFile(path string) bool {
+	if path == StdinPath {
+		return true
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml", ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// Parse decodes configuration data in the given format
+func Parse(data []byte, format string) (*Config, error) {
+	var config Config
+
+	switch format {
+	case FormatTOML:
+		if err := toml.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse TOML: %w", err)
+		}
+	case FormatYAML:
+		if err := yaml.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
+	case FormatJSON:
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config format: %s", format)
+	}
+
+	return &config, nil
+}
+
+// Marshal encodes a configuration in the given format. TOML is written by ToTOML, which
+// omits unset fields so the output validates against the schema.
+func Marshal(config *Config, format string) ([]byte, error) {
+	switch format {
+	case FormatTOML:
+		content, err := ToTOML(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode TOML: %w", err)
+		}
+		return []byte(content), nil
+	case FormatYAML:
+		data, err := yaml.Marshal(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode YAML: %w", err)
+		}
+		return data, nil
+	case FormatJSON:
+		data, err := json.MarshalIndent(config, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		return append(data, '\n'), nil
+	default:
+		return nil, fmt.Errorf("unsupported config format: %s", format)
+	}
+}
+
+// detectFormat guesses the format of configuration data read without a file name
+func detectFormat(data []byte) string {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		return FormatJSON
+	}
+
+	var probe map[string]interface{}
+	if toml.Unmarshal(data, &probe) == nil {
+		return FormatTOML
+	}
+	if yaml.Unmarshal(data, &probe) == nil {
+		return FormatYAML
+	}
+
+	// Report parse errors against the historical default
+	return FormatTOML
+}
@@ -0,0 +1,282 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const yamlConfigContent = `sites:
+  - remote: https://github.com/
+    dir: ./repos/
+    warm_up_all: true
+    repos:
+      - repo: golang/example
+        rename: example-go
+        memo: Test repository
+profiles:
+  ci:
+    depth: 1
+    warm_up: false
+`
+
+const jsonConfigContent = `{
+  "sites": [
+    {
+      "remote": "https://github.com/",
+      "dir": "./repos/",
+      "warm_up_all": true,
+      "repos": [
+        {"repo": "golang/example", "rename": "example-go", "memo": "Test repository"}
+      ]
+    }
+  ],
+  "profiles": {"ci": {"depth": 1, "warm_up": false}}
+}`
+
+func assertFormatConfig(t *testing.T, cfg *Config) {
+	t.Helper()
+	if len(cfg.Sites) != 1 {
+		t.Fatalf("Expected 1 site, got %d", len(cfg.Sites))
+	}
+	site := cfg.Sites[0]
+	if site.RemotePrefix != "https://github.com/" || site.Dir != "./repos/" || !site.WarmUpAll {
+		t.Errorf("Unexpected site: %+v", site)
+	}
+	if len(site.Repos) != 1 {
+		t.Fatalf("Expected 1 repo, got %d", len(site.Repos))
+	}
+	repo := site.Repos[0]
+	if repo.Repo != "golang/example" || repo.Rename != "example-go" || repo.Memo != "Test repository" {
+		t.Errorf("Unexpected repo: %+v", repo)
+	}
+	ci, ok := cfg.Profiles["ci"]
+	if !ok || ci.Depth == nil || *ci.Depth != 1 || ci.WarmUp == nil || *ci.WarmUp {
+		t.Errorf("Unexpected ci profile: %+v", ci)
+	}
+}
+
+func TestReadFromFile_YAMLAndJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"repos.yaml", yamlConfigContent},
+		{"repos.yml", yamlConfigContent},
+		{"repos.json", jsonConfigContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), tt.name)
+			if err := os.WriteFile(configFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test config: %v", err)
+			}
+
+			cfg, err := ReadFromFile(configFile)
+			if err != nil {
+				t.Fatalf("ReadFromFile failed: %v", err)
+			}
+			assertFormatConfig(t, cfg)
+		})
+	}
+}
+
+func TestReadFromFile_InvalidYAMLAndJSON(t *testing.T) {
+	tests := map[string]string{
+		"bad.yaml": "sites: [\n  - remote",
+		"bad.json": `{"sites": [`,
+	}
+
+	for name, content := range tests {
+		configFile := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test config: %v", err)
+		}
+		if _, err := ReadFromFile(configFile); err == nil {
+			t.Errorf("Expected parse error for %s", name)
+		}
+	}
+}
+
+func TestReadFromFile_Stdin(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"toml", `[[sites]]
+remote = "https://github.com/"
+dir = "./repos/"
+warm_up_all = true
+
+[[sites.repos]]
+repo = "golang/example"
+rename = "example-go"
+memo = "Test repository"
+
+[profiles.ci]
+depth = 1
+warm_up = false
+`},
+		{"yaml", yamlConfigContent},
+		{"json", jsonConfigContent},
+	}
+
+	originalStdin := stdin
+	defer func() { stdin = originalStdin }()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdin = strings.NewReader(tt.content)
+
+			cfg, err := ReadFromFile(StdinPath)
+			if err != nil {
+				t.Fatalf("ReadFromFile(stdin) failed: %v", err)
+			}
+			assertFormatConfig(t, cfg)
+		})
+	}
+}
+
+func TestSaveToFile_Formats(t *testing.T) {
+	warmUp := false
+	depth := 1
+	original := Config{
+		Sites: []SiteConfig{
+			{
+				RemotePrefix: "https://github.com/",
+				Dir:          "./repos/",
+				WarmUpAll:    true,
+				Repos: []Repo{
+					{Repo: "golang/example", Rename: "example-go", Memo: "Test repository"},
+				},
+			},
+		},
+		Profiles: map[string]Profile{"ci": {Depth: &depth, WarmUp: &warmUp}},
+	}
+
+	for _, name := range []string{"out.toml", "out.yaml", "out.json"} {
+		t.Run(name, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), name)
+			if err := SaveToFile(original, configFile); err != nil {
+				t.Fatalf("SaveToFile failed: %v", err)
+			}
+
+			loaded, err := ReadFromFile(configFile)
+			if err != nil {
+				t.Fatalf("Failed to reload saved config: %v", err)
+			}
+			assertFormatConfig(t, loaded)
+		})
+	}
+}
+
+func TestMarshal_MinimalJSON(t *testing.T) {
+	source := `[[sites]]
+remote = "https://github.com/"
+dir = "./repos/"
+
+[[sites.repos]]
+repo = "golang/example"
+`
+	cfg, err := Parse([]byte(source), FormatTOML)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	// 未设置的 defaults 和 hooks 不会输出为空对象
+	data, err := Marshal(cfg, FormatJSON)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	expected := `{
+  "sites": [
+    {
+      "remote": "https://github.com/",
+      "dir": "./repos/",
+      "repos": [
+        {
+          "repo": "golang/example"
+        }
+      ]
+    }
+  ]
+}
+`
+	if string(data) != expected {
+		t.Errorf("Unexpected JSON:\n%s\nwant:\n%s", data, expected)
+	}
+
+	// TOML → JSON → TOML 往返不引入多余内容
+	roundTrip, err := Parse(data, FormatJSON)
+	if err != nil {
+		t.Fatalf("Parse JSON failed: %v", err)
+	}
+	before, _ := ToTOML(cfg)
+	after, _ := ToTOML(roundTrip)
+	if before != after {
+		t.Errorf("Round trip changed the TOML:\n%s\nwant:\n%s", after, before)
+	}
+}
+
+func TestSaveToFile_Stdout(t *testing.T) {
+	var buf bytes.Buffer
+	originalStdout := stdout
+	stdout = &buf
+	defer func() { stdout = originalStdout }()
+
+	cfg := Config{Sites: []SiteConfig{{RemotePrefix: "https://github.com/", Dir: "./repos/"}}}
+	if err := SaveToFile(cfg, StdinPath); err != nil {
+		t.Fatalf("SaveToFile(stdout) failed: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), `remote = "https://github.com/"`) {
+		t.Errorf("Expected TOML on stdout, got:\n%s", buf.String())
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	tests := map[string]string{
+		"repos.toml":     FormatTOML,
+		"repos.YAML":     FormatYAML,
+		"repos.yml":      FormatYAML,
+		"dir/repos.json": FormatJSON,
+		"repos":          FormatTOML,
+		"repos.unknown":  FormatTOML,
+	}
+
+	for path, expected := range tests {
+		if got := FormatFromPath(path); got != expected {
+			t.Errorf("FormatFromPath(%q) = %s, expected %s", path, got, expected)
+		}
+	}
+}
+
+func TestIsConfigFile(t *testing.T) {
+	tests := map[string]bool{
+		"repos.toml": true,
+		"repos.yaml": true,
+		"repos.yml":  true,
+		"repos.json": true,
+		"-":          true,
+		"repos.txt":  false,
+		"mkconf":     false,
+	}
+
+	for path, expected := range tests {
+		if got := IsConfigFile(path); got != expected {
+			t.Errorf("IsConfigFile(%q) = %v, expected %v", path, got, expected)
+		}
+	}
+}
+
+func TestParse_UnsupportedFormat(t *testing.T) {
+	if _, err := Parse([]byte(""), "ini"); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+	if _, err := Marshal(&Config{}, "ini"); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+}
@@ -9,37 +9,45 @@ import (
 
 	"github.com/khicago/repoll/internal/git"
 	"github.com/khicago/repoll/internal/reporter"
+	"github.com/khicago/repoll/internal/warmup"
 )
 
-// GenerateFromDirectory generates a configuration by scanning a directory for Git repositories
+// siteKey identifies a generated site: repositories share a site only when they come from the
+// same remote and live in the same parent directory
+type siteKey struct {
+	remotePrefix string
+	dir          string
+}
+
+// GenerateFromDirectory generates a configuration by scanning a directory for Git repositories.
+// Site directories are relative to targetDir, so running the configuration from targetDir
+// reproduces the scanned layout.
 func GenerateFromDirectory(targetDir string, report *reporter.MkconfReport) (*Config, error) {
+	return GenerateFromDirectoryWithOptions(targetDir, report, GenerateOptions{})
+}
+
+// GenerateFromDirectoryWithOptions generates a configuration using the given search options
+func GenerateFromDirectoryWithOptions(targetDir string, report *reporter.MkconfReport, opts GenerateOptions) (*Config, error) {
 	config := &Config{
 		Sites: make([]SiteConfig, 0),
 	}
 
-	siteMap := make(map[string]*SiteConfig)
-
-	err := filepath.Walk(targetDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+	siteIndex := make(map[siteKey]int)
 
-		if !info.IsDir() {
-			return nil
-		}
+	paths, err := findRepositories(targetDir, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory: %w", err)
+	}
 
-		// Check if this directory is a Git repository
-		gitDir := filepath.Join(path, ".git")
-		if _, err := os.Stat(gitDir); os.IsNotExist(err) {
-			return nil
-		}
+	discoveredRepos := discoverRepositories(paths, opts.Workers)
+	primary := primaryCheckouts(discoveredRepos)
 
-		// Discover repository information
-		repoInfo, err := git.DiscoverRepository(path)
-		if err != nil {
+	for _, discovered := range discoveredRepos {
+		path, repoInfo := discovered.path, discovered.info
+		if discovered.err != nil {
 			// Log but continue with other repositories
-			fmt.Printf("Warning: Failed to discover repository at %s: %v\n", path, err)
-			return nil
+			opts.warn("Failed to discover repository at %s: %v", path, discovered.err)
+			continue
 		}
 
 		// Add to report
@@ -51,46 +59,50 @@ func GenerateFromDirectory(targetDir string, report *reporter.MkconfReport) (*Co
 				HasOrigin:   repoInfo.HasOrigin,
 				Uncommitted: repoInfo.Uncommitted,
 				Unmerged:    repoInfo.Unmerged,
+				Kind:        repoInfo.Kind,
+			}
+			if repoInfo.HasOrigin {
+				action.Site = remotePrefixFor(repoInfo.Origin, git.ExtractRepoNameFromURL(repoInfo.Origin))
 			}
 			report.Actions = append(report.Actions, action)
 		}
 
+		// Emit each repository once, from its primary checkout
+		if !primary[path] {
+			if repoInfo.Kind == git.KindSubmodule {
+				opts.warn("Skipping submodule checkout %s (part of %s)", path, repoInfo.Superproject)
+			} else {
+				opts.warn("Skipping %s %s (repository already listed)", repoInfo.Kind, path)
+			}
+			continue
+		}
+
 		if !repoInfo.HasOrigin {
-			fmt.Printf("Skipping repository without origin: %s\n", path)
-			return nil
+			opts.warn("Skipping repository without origin: %s", path)
+			continue
 		}
 
 		// Extract repository name and remote prefix
 		repoName := git.ExtractRepoNameFromURL(repoInfo.Origin)
-		remotePrefix := git.ExtractRemotePrefix(repoInfo.Origin)
+		remotePrefix := remotePrefixFor(repoInfo.Origin, repoName)
 
 		if repoName == "" || remotePrefix == "" {
-			fmt.Printf("Warning: Could not parse repository info for %s\n", path)
-			return nil
+			opts.warn("Could not parse repository info for %s", path)
+			continue
 		}
 
-		// Get or create site configuration
-		site, exists := siteMap[remotePrefix]
+		// Get or create the site for this remote and parent directory
+		key := siteKey{remotePrefix: remotePrefix, dir: siteDirFor(targetDir, path)}
+		index, exists := siteIndex[key]
 		if !exists {
-			// Determine appropriate directory
-			relPath, err := filepath.Rel(targetDir, filepath.Dir(path))
-			if err != nil {
-				relPath = filepath.Dir(path)
-			}
-			if relPath == "." {
-				relPath = "./"
-			} else {
-				relPath = "./" + relPath + "/"
-			}
-
-			site = &SiteConfig{
-				RemotePrefix: remotePrefix,
-				Dir:          relPath,
+			index = len(config.Sites)
+			siteIndex[key] = index
+			config.Sites = append(config.Sites, SiteConfig{
+				RemotePrefix: key.remotePrefix,
+				Dir:          key.dir,
 				Repos:        make([]Repo, 0),
 				WarmUpAll:    false,
-			}
-			siteMap[remotePrefix] = site
-			config.Sites = append(config.Sites, *site)
+			})
 		}
 
 		// Create repository configuration
@@ -100,26 +112,37 @@ func GenerateFromDirectory(targetDir string, report *reporter.MkconfReport) (*Co
 			Memo:   generateMemoFromPath(path),
 		}
 
-		// Check if we need to use a custom name
-		expectedPath := filepath.Join(site.Dir, strings.Split(repoName, "/")[len(strings.Split(repoName, "/"))-1])
-		actualPath, _ := filepath.Rel(targetDir, path)
-		if actualPath != expectedPath {
+		// Use a custom name when the clone directory differs from the repository name
+		repoParts := strings.Split(repoName, "/")
+		if filepath.Base(path) != repoParts[len(repoParts)-1] {
 			repo.Rename = filepath.Base(path)
 		}
 
-		// Add to the correct site
-		for i := range config.Sites {
-			if config.Sites[i].RemotePrefix == remotePrefix {
-				config.Sites[i].Repos = append(config.Sites[i].Repos, repo)
-				break
+		// Record the checked out branch, or the commit of a detached checkout such as a tag:
+		// a tag recorded as branch would be pulled like a branch on the next update
+		if detached, err := git.IsDetached(path); err == nil {
+			if !detached {
+				if branch, err := git.GetCurrentBranch(path); err == nil {
+					repo.Branch = &branch
+				}
+			} else if commit, err := git.GetHeadCommit(path); err == nil {
+				repo.Commit = commit
+			}
+		}
+		if opts.PinCommits && repo.Commit == "" {
+			if commit, err := git.GetHeadCommit(path); err == nil {
+				repo.Commit = commit
 			}
 		}
 
-		return nil
-	})
+		if opts.RecordLinks {
+			repo.Worktrees = linkedWorktrees(path)
+			if _, err := os.Stat(filepath.Join(path, ".gitmodules")); err == nil {
+				repo.Submodules = true
+			}
+		}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to walk directory: %w", err)
+		config.Sites[index].Repos = append(config.Sites[index].Repos, repo)
 	}
 
 	if len(config.Sites) == 0 {
@@ -129,6 +152,28 @@ func GenerateFromDirectory(targetDir string, report *reporter.MkconfReport) (*Co
 	return config, nil
 }
 
+// siteDirFor returns the site directory of a repository, relative to the scanned directory
+func siteDirFor(targetDir, repoPath string) string {
+	relPath, err := filepath.Rel(targetDir, filepath.Dir(repoPath))
+	if err != nil {
+		return filepath.ToSlash(filepath.Dir(repoPath)) + "/"
+	}
+	if relPath == "." {
+		return "./"
+	}
+	return "./" + filepath.ToSlash(relPath) + "/"
+}
+
+// remotePrefixFor returns the remote prefix that, joined with repoName, yields the origin URL.
+// URLs with extra path segments (e.g. GitLab subgroups) keep them in the prefix.
+func remotePrefixFor(origin, repoName string) string {
+	trimmed := strings.TrimSuffix(strings.TrimSpace(origin), ".git")
+	if strings.Contains(trimmed, "://") && repoName != "" && strings.HasSuffix(trimmed, "/"+repoName) {
+		return strings.TrimSuffix(trimmed, repoName)
+	}
+	return git.ExtractRemotePrefix(origin)
+}
+
 // shouldDefaultWarmUp determines if warm-up should be enabled by default based on project type
 func shouldDefaultWarmUp(path string) bool {
 	// Check for common project files that indicate warm-up should be enabled
@@ -139,6 +184,7 @@ func shouldDefaultWarmUp(path string) bool {
 		"Cargo.toml",      // Rust projects
 		"pom.xml",         // Maven projects
 		"build.gradle",    // Gradle projects
+		"build.gradle.kts", // Gradle projects with Kotlin DSL
 	}
 
 	for _, indicator := range warmupIndicators {
@@ -147,38 +193,6 @@ func shouldDefaultWarmUp(path string) bool {
 		}
 	}
 
-	return false
+	// Monorepos keep their projects in subdirectories
+	return len(warmup.DetectProjects(path, warmup.MaxProjectDepth)) > 0
 }
-
-// generateMemoFromPath generates a memo based on the repository path
-func generateMemoFromPath(path string) string {
-	// Try to read common documentation files for a description
-	readmeFiles := []string{"README.md", "README.rst", "README.txt", "README"}
-	
-	for _, readme := range readmeFiles {
-		readmePath := filepath.Join(path, readme)
-		if content, err := os.ReadFile(readmePath); err == nil {
-			lines := strings.Split(string(content), "\n")
-			for _, line := range lines {
-				line = strings.TrimSpace(line)
-				if len(line) > 10 && len(line) < 100 && !strings.HasPrefix(line, "#") {
-					// Try to find a descriptive line
-					if strings.Contains(strings.ToLower(line), "description") ||
-						strings.Contains(strings.ToLower(line), "about") {
-						return line
-					}
-				}
-			}
-			// If no description found, use the first substantial line
-			for _, line := range lines {
-				line = strings.TrimSpace(line)
-				line = strings.TrimPrefix(line, "# ")
-				if len(line) > 10 && len(line) < 100 {
-					return line
-				}
-			}
-		}
-	}
-
-	return ""
-} 
\ No newline at end of file
@@ -1,11 +1,15 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 
+	"github.com/khicago/repoll/internal/git"
 	"github.com/khicago/repoll/internal/reporter"
 )
 
@@ -76,7 +80,10 @@ func TestGenerateFromDirectory_WithGitRepo(t *testing.T) {
 	
 	// 验证报告
 	if report == nil || len(report.Actions) != 1 {
-		t.Errorf("Expected 1 action in report, got %d", len(report.Actions))
+		t.Fatalf("Expected 1 action in report, got %d", len(report.Actions))
+	}
+	if report.Actions[0].Site != "https://github.com/" {
+		t.Errorf("Expected report site 'https://github.com/', got %s", report.Actions[0].Site)
 	}
 }
 
@@ -97,11 +104,17 @@ func TestGenerateFromDirectory_NoOrigin(t *testing.T) {
 		t.Skipf("Git not available, skipping test: %v", err)
 	}
 	
-	// 不添加origin，应该被跳过
-	_, err = GenerateFromDirectory(tempDir, nil)
+	// 不添加origin，应该被跳过，并通过 Warn 而不是标准输出报告
+	var warnings []string
+	_, err = GenerateFromDirectoryWithOptions(tempDir, nil, GenerateOptions{
+		Warn: func(format string, args ...interface{}) { warnings = append(warnings, fmt.Sprintf(format, args...)) },
+	})
 	if err == nil {
 		t.Error("Expected error for directory with repositories without origin")
 	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "Skipping repository without origin") {
+		t.Errorf("Unexpected warnings: %v", warnings)
+	}
 }
 
 func TestGenerateFromDirectory_MultipleRepos(t *testing.T) {
@@ -169,6 +182,86 @@ func TestGenerateFromDirectory_MultipleRepos(t *testing.T) {
 	}
 }
 
+func TestGenerateFromDirectory_SiteGrouping(t *testing.T) {
+	tempDir := t.TempDir()
+	
+	// 同一主机的仓库位于不同目录，其中一个使用了自定义目录名
+	repos := []struct {
+		path   string
+		origin string
+	}{
+		{"apps/api", "https://github.com/team/api.git"},
+		{"apps/web-local", "https://github.com/team/web.git"},
+		{"libs/util", "https://github.com/team/util.git"},
+		{"libs/sub", "https://gitlab.com/group/subgroup/sub.git"},
+	}
+	
+	for _, repo := range repos {
+		repoDir := filepath.Join(tempDir, repo.path)
+		if err := os.MkdirAll(repoDir, 0755); err != nil {
+			t.Fatalf("Failed to create repo directory: %v", err)
+		}
+		
+		cmd := exec.Command("git", "init")
+		cmd.Dir = repoDir
+		if err := cmd.Run(); err != nil {
+			t.Skipf("Git not available, skipping test: %v", err)
+		}
+		
+		cmd = exec.Command("git", "remote", "add", "origin", repo.origin)
+		cmd.Dir = repoDir
+		if err := cmd.Run(); err != nil {
+			t.Fatalf("Failed to add origin: %v", err)
+		}
+	}
+	
+	config, err := GenerateFromDirectory(tempDir, nil)
+	if err != nil {
+		t.Fatalf("GenerateFromDirectory failed: %v", err)
+	}
+	
+	if len(config.Sites) != 3 {
+		t.Fatalf("Expected 3 sites, got %d: %+v", len(config.Sites), config.Sites)
+	}
+	
+	// 每个仓库的 URL 和本地路径都应与磁盘上的一致
+	for _, repo := range repos {
+		found := false
+		for _, site := range config.Sites {
+			for _, r := range site.Repos {
+				if r.RepoUrl(site) != repo.origin {
+					continue
+				}
+				found = true
+				if got := r.FullPath(site); got != filepath.FromSlash(repo.path) {
+					t.Errorf("Expected path %s for %s, got %s", repo.path, repo.origin, got)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("Repository %s not found in generated config", repo.origin)
+		}
+	}
+}
+
+func TestRemotePrefixFor(t *testing.T) {
+	tests := []struct {
+		origin   string
+		expected string
+	}{
+		{"https://github.com/owner/repo.git", "https://github.com/"},
+		{"https://gitlab.com/group/subgroup/repo.git", "https://gitlab.com/group/"},
+		{"file:///srv/mirrors/owner/repo", "file:///srv/mirrors/"},
+		{"git@github.com:owner/repo.git", "https://github.com/"},
+	}
+	
+	for _, tt := range tests {
+		if got := remotePrefixFor(tt.origin, git.ExtractRepoNameFromURL(tt.origin)); got != tt.expected {
+			t.Errorf("remotePrefixFor(%q) = %q, want %q", tt.origin, got, tt.expected)
+		}
+	}
+}
+
 func TestShouldDefaultWarmUp(t *testing.T) {
 	tempDir := t.TempDir()
 	
@@ -212,6 +305,11 @@ func TestShouldDefaultWarmUp(t *testing.T) {
 			files:    []string{"go.mod", "package.json"},
 			expected: true,
 		},
+		{
+			name:     "Monorepo project",
+			files:    []string{"services/api/go.mod"},
+			expected: true,
+		},
 		{
 			name:     "No indicators",
 			files:    []string{"README.md", "LICENSE"},
@@ -236,6 +334,9 @@ func TestShouldDefaultWarmUp(t *testing.T) {
 			// 创建测试文件
 			for _, file := range test.files {
 				filePath := filepath.Join(testDir, file)
+				if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+					t.Fatalf("Failed to create test file directory: %v", err)
+				}
 				err := os.WriteFile(filePath, []byte("test content"), 0644)
 				if err != nil {
 					t.Fatalf("Failed to create test file: %v", err)
@@ -396,4 +497,239 @@ func TestGenerateFromDirectory_WithReport(t *testing.T) {
 	if !action.HasOrigin {
 		t.Error("Expected HasOrigin to be true")
 	}
-} 
\ No newline at end of file
+} 
+func TestGenerateFromDirectoryWithOptions_Pruning(t *testing.T) {
+	tempDir := t.TempDir()
+	
+	repos := map[string]string{
+		"app":                    "https://github.com/team/app.git",
+		"app/plugins/plugin":     "https://github.com/team/plugin.git",
+		"app/node_modules/dep":   "https://github.com/team/dep.git",
+		"archive/old":            "https://github.com/team/old.git",
+		"tmp-scratch":            "https://github.com/team/scratch.git",
+		"deep/level1/level2/lib": "https://github.com/team/lib.git",
+	}
+	for dir, origin := range repos {
+		repoDir := filepath.Join(tempDir, dir)
+		if err := os.MkdirAll(repoDir, 0755); err != nil {
+			t.Fatalf("Failed to create repo directory: %v", err)
+		}
+		
+		cmd := exec.Command("git", "init")
+		cmd.Dir = repoDir
+		if err := cmd.Run(); err != nil {
+			t.Skipf("Git not available, skipping test: %v", err)
+		}
+		
+		cmd = exec.Command("git", "remote", "add", "origin", origin)
+		cmd.Dir = repoDir
+		if err := cmd.Run(); err != nil {
+			t.Fatalf("Failed to add origin: %v", err)
+		}
+	}
+	
+	ignore := "# old stuff\narchive/\n"
+	if err := os.WriteFile(filepath.Join(tempDir, IgnoreFileName), []byte(ignore), 0644); err != nil {
+		t.Fatalf("Failed to create ignore file: %v", err)
+	}
+	
+	tests := []struct {
+		name     string
+		opts     GenerateOptions
+		expected []string
+	}{
+		// 默认在仓库根目录停止，并遵循 .repollignore
+		{"default", GenerateOptions{}, []string{"team/app", "team/lib", "team/scratch"}},
+		// --nested 进入仓库内部，但仍跳过 node_modules
+		{"nested", GenerateOptions{Nested: true, Exclude: []string{"tmp-*"}, MaxDepth: 3}, []string{"team/app", "team/plugin"}},
+		{"max depth", GenerateOptions{MaxDepth: 1, Workers: 1}, []string{"team/app", "team/scratch"}},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := GenerateFromDirectoryWithOptions(tempDir, nil, tt.opts)
+			if err != nil {
+				t.Fatalf("GenerateFromDirectoryWithOptions failed: %v", err)
+			}
+			
+			var found []string
+			for _, site := range config.Sites {
+				found = append(found, repoNames(site.Repos)...)
+			}
+			sort.Strings(found)
+			if strings.Join(found, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected %v, got %v", tt.expected, found)
+			}
+		})
+	}
+}
+
+func TestMatchIgnore(t *testing.T) {
+	patterns := []string{"build", "/top", "./anchored", "team/*-old", ".cache/"}
+	
+	tests := map[string]bool{
+		"build":            true,
+		"src/build":        true,
+		"top":              true,
+		"src/top":          false,
+		"anchored":         true,
+		"src/anchored":     false,
+		"team/service-old": true,
+		"team/service":     false,
+		"a/.cache":         true,
+	}
+	
+	for rel, expected := range tests {
+		if got := matchIgnore(patterns, rel); got != expected {
+			t.Errorf("matchIgnore(%q) = %v, want %v", rel, got, expected)
+		}
+	}
+}
+
+func TestGenerateFromDirectoryWithOptions_RepositoryKinds(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("Git not available, skipping test")
+	}
+	
+	upstream := filepath.Join(t.TempDir(), "app.git")
+	runGitCommand(t, "", "init", upstream)
+	runGitCommand(t, upstream, "commit", "--allow-empty", "-m", "initial")
+	
+	// 主克隆、链接工作树、子模块以及带工作树的裸仓库
+	workspace := t.TempDir()
+	app := filepath.Join(workspace, "app")
+	runGitCommand(t, "", "clone", "file://"+upstream, app)
+	runGitCommand(t, app, "worktree", "add", "-b", "feature", "../app-feature")
+	runGitCommand(t, app, "-c", "protocol.file.allow=always", "submodule", "add", "file://"+upstream, "lib")
+	
+	mirror := filepath.Join(workspace, "mirror.git")
+	runGitCommand(t, "", "clone", "--bare", "file://"+upstream, mirror)
+	runGitCommand(t, mirror, "worktree", "add", "../mirror-main")
+	
+	config, err := GenerateFromDirectoryWithOptions(workspace, nil, GenerateOptions{Nested: true, RecordLinks: true})
+	if err != nil {
+		t.Fatalf("GenerateFromDirectoryWithOptions failed: %v", err)
+	}
+	
+	if len(config.Sites) != 1 {
+		t.Fatalf("Expected 1 site, got %+v", config.Sites)
+	}
+	repos := config.Sites[0].Repos
+	if len(repos) != 2 {
+		t.Fatalf("Expected the clone and the bare repository's worktree only, got %+v", repos)
+	}
+	
+	// 普通克隆优先，工作树和子模块作为关联关系记录
+	if filepath.Base(repos[0].FullPath(config.Sites[0])) != "app" || !repos[0].Submodules {
+		t.Errorf("Unexpected primary clone: %+v", repos[0])
+	}
+	if len(repos[0].Worktrees) != 1 || repos[0].Worktrees[0] != (Worktree{Path: "../app-feature", Branch: "feature"}) {
+		t.Errorf("Unexpected worktrees: %+v", repos[0].Worktrees)
+	}
+	
+	// 裸仓库由其第一个工作树代表
+	if repos[1].Rename != "mirror-main" {
+		t.Errorf("Expected bare repository to be represented by its worktree, got %+v", repos[1])
+	}
+}
+
+// runGitCommand 运行Git命令，失败时终止测试
+func runGitCommand(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, output)
+	}
+}
+
+func TestToTOML_RepositoryLinks(t *testing.T) {
+	cfg := &Config{
+		Sites: []SiteConfig{
+			{
+				RemotePrefix: "https://github.com/",
+				Dir:          "./repos/",
+				Repos: []Repo{{
+					Repo:       "team/platform",
+					Submodules: true,
+					Worktrees:  []Worktree{{Path: "../platform-release", Branch: "release"}, {Path: "../platform-scratch"}},
+				}},
+			},
+		},
+	}
+	
+	content, err := ToTOML(cfg)
+	if err != nil {
+		t.Fatalf("ToTOML failed: %v", err)
+	}
+	
+	expected := `worktrees = [{ path = "../platform-release", branch = "release" }, { path = "../platform-scratch" }]`
+	if !strings.Contains(content, expected) || !strings.Contains(content, "submodules = true") {
+		t.Errorf("Unexpected TOML:\n%s", content)
+	}
+	
+	// 生成的 TOML 应能解析回相同的结构
+	parsed, err := Parse([]byte(content), FormatTOML)
+	if err != nil {
+		t.Fatalf("Failed to parse generated TOML: %v", err)
+	}
+	repo := parsed.Sites[0].Repos[0]
+	if !repo.Submodules || len(repo.Worktrees) != 2 || repo.Worktrees[0].Branch != "release" {
+		t.Errorf("Unexpected parsed repo: %+v", repo)
+	}
+}
+
+func TestGenerateFromDirectoryWithOptions_BranchAndPin(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("Git not available, skipping test")
+	}
+	
+	upstream := filepath.Join(t.TempDir(), "app.git")
+	runGitCommand(t, "", "init", upstream)
+	runGitCommand(t, upstream, "commit", "--allow-empty", "-m", "first")
+	runGitCommand(t, upstream, "tag", "v1.0.0")
+	runGitCommand(t, upstream, "commit", "--allow-empty", "-m", "second")
+	runGitCommand(t, upstream, "branch", "develop")
+	
+	// 分别处于分支、标签和分离提交上的克隆
+	workspace := t.TempDir()
+	for _, name := range []string{"on-branch", "on-tag", "detached"} {
+		runGitCommand(t, "", "clone", "-q", "file://"+upstream, filepath.Join(workspace, name))
+	}
+	runGitCommand(t, filepath.Join(workspace, "on-branch"), "checkout", "-q", "develop")
+	runGitCommand(t, filepath.Join(workspace, "on-tag"), "checkout", "-q", "v1.0.0")
+	runGitCommand(t, filepath.Join(workspace, "detached"), "checkout", "-q", "--detach", "HEAD")
+	
+	refs := func(opts GenerateOptions) map[string]Repo {
+		config, err := GenerateFromDirectoryWithOptions(workspace, nil, opts)
+		if err != nil {
+			t.Fatalf("GenerateFromDirectoryWithOptions failed: %v", err)
+		}
+		repos := make(map[string]Repo)
+		for _, repo := range config.Sites[0].Repos {
+			repos[repo.Rename] = repo
+		}
+		return repos
+	}
+	
+	repos := refs(GenerateOptions{})
+	if repos["on-branch"].Branch == nil || *repos["on-branch"].Branch != "develop" || repos["on-branch"].Commit != "" {
+		t.Errorf("Unexpected branch checkout: %+v", repos["on-branch"])
+	}
+	// 标签检出记录为提交，避免下次更新时被当作分支拉取
+	tagCommit, _ := git.GetHeadCommit(filepath.Join(workspace, "on-tag"))
+	if repos["on-tag"].Branch != nil || repos["on-tag"].Commit != tagCommit {
+		t.Errorf("Unexpected tag checkout: %+v", repos["on-tag"])
+	}
+	if repos["detached"].Branch != nil || !git.IsCommitHash(repos["detached"].Commit) {
+		t.Errorf("Unexpected detached checkout: %+v", repos["detached"])
+	}
+	
+	// --pin-commits 为每个仓库记录提交哈希
+	for name, repo := range refs(GenerateOptions{PinCommits: true}) {
+		if !git.IsCommitHash(repo.Commit) {
+			t.Errorf("Expected pinned commit for %s, got %+v", name, repo)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package config
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Memo length limits: shorter README text is usually a fragment, longer text is cut
+const (
+	minReadmeMemoLength = 10
+	maxMemoLength       = 100
+)
+
+// memoSources are tried in order; the first non-empty description becomes the memo
+var memoSources = []func(dir string) string{
+	packageJSONDescription,
+	cargoDescription,
+	pyprojectDescription,
+	pomDescription,
+	goModulePath,
+	readmeDescription,
+}
+
+var (
+	markdownImage     = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	markdownLink      = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	markdownRefLink   = regexp.MustCompile(`\[([^\]]*)\]\[[^\]]*\]`)
+	markdownLinkDef   = regexp.MustCompile(`^\[[^\]]+\]:\s`)
+	markdownEmphasis  = regexp.MustCompile("(\\*\\*|__|\\*|`)")
+	htmlTag           = regexp.MustCompile(`<[^>]+>`)
+	underlinePattern  = regexp.MustCompile(`^[=\-~^*#+]{3,}$`)
+	orderedListMarker = regexp.MustCompile(`^\d+[.)]\s`)
+)
+
+// generateMemoFromPath generates a memo from the project metadata or README of a repository
+func generateMemoFromPath(path string) string {
+	for _, source := range memoSources {
+		if memo := normalizeMemo(source(path)); memo != "" {
+			return memo
+		}
+	}
+	return ""
+}
+
+// packageJSONDescription reads the description of a Node.js package
+func packageJSONDescription(dir string) string {
+	var pkg struct {
+		Description string `json:"description"`
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil || json.Unmarshal(data, &pkg) != nil {
+		return ""
+	}
+	return pkg.Description
+}
+
+// cargoDescription reads [package].description from Cargo.toml
+func cargoDescription(dir string) string {
+	var manifest struct {
+		Package struct {
+			Description string `toml:"description"`
+		} `toml:"package"`
+	}
+	if _, err := toml.DecodeFile(filepath.Join(dir, "Cargo.toml"), &manifest); err != nil {
+		return ""
+	}
+	return manifest.Package.Description
+}
+
+// pyprojectDescription reads [project].description or [tool.poetry].description from pyproject.toml
+func pyprojectDescription(dir string) string {
+	var manifest struct {
+		Project struct {
+			Description string `toml:"description"`
+		} `toml:"project"`
+		Tool struct {
+			Poetry struct {
+				Description string `toml:"description"`
+			} `toml:"poetry"`
+		} `toml:"tool"`
+	}
+	if _, err := toml.DecodeFile(filepath.Join(dir, "pyproject.toml"), &manifest); err != nil {
+		return ""
+	}
+	if manifest.Project.Description != "" {
+		return manifest.Project.Description
+	}
+	return manifest.Tool.Poetry.Description
+}
+
+// pomDescription reads the project <description> from a Maven pom.xml
+func pomDescription(dir string) string {
+	var pom struct {
+		Description string `xml:"description"`
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "pom.xml"))
+	if err != nil || xml.Unmarshal(data, &pom) != nil {
+		return ""
+	}
+	return pom.Description
+}
+
+// goModulePath reads the module path from go.mod
+func goModulePath(dir string) string {
+	file, err := os.Open(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
+	}
+	return ""
+}
+
+// readmeDescription returns the first prose paragraph of a README, skipping badges,
+// HTML, code blocks and headings. The first heading is used when there is no paragraph.
+func readmeDescription(dir string) string {
+	for _, name := range []string{"README.md", "README.rst", "README.txt", "README"} {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		paragraph, title := parseReadme(string(content))
+		for _, candidate := range []string{paragraph, title} {
+			if len(normalizeMemo(candidate)) >= minReadmeMemoLength {
+				return candidate
+			}
+		}
+	}
+	return ""
+}
+
+// parseReadme extracts the first prose paragraph and the first heading of a README
+func parseReadme(content string) (paragraph, title string) {
+	var lines []string
+	inFence, inComment := false, false
+
+	for _, raw := range strings.Split(content, "\n") {
+		line := strings.TrimSpace(raw)
+
+		switch {
+		case inComment:
+			inComment = !strings.Contains(line, "-->")
+			continue
+		case strings.HasPrefix(line, "<!--"):
+			inComment = !strings.Contains(line, "-->")
+			continue
+		case strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~"):
+			inFence = !inFence
+			continue
+		case inFence:
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") {
+			if heading := strings.TrimSpace(strings.TrimLeft(line, "#")); title == "" && heading != "" {
+				title = stripMarkdown(heading)
+			}
+			if len(lines) > 0 {
+				break
+			}
+			continue
+		}
+
+		text := readmeProse(line)
+		if text == "" {
+			// A setext underline turns the preceding line into a heading
+			if underlinePattern.MatchString(line) && len(lines) == 1 {
+				if title == "" {
+					title = lines[0]
+				}
+				lines = nil
+				continue
+			}
+			if len(lines) > 0 {
+				break
+			}
+			continue
+		}
+		// Short fragments such as taglines or stray words do not start a paragraph
+		if len(lines) == 0 && len(text) < minReadmeMemoLength {
+			continue
+		}
+		lines = append(lines, text)
+	}
+
+	return strings.Join(lines, " "), title
+}
+
+// readmeProse returns the prose of a README line, or "" for markup-only lines
+// such as badges, HTML, tables, lists, directives and link definitions
+func readmeProse(line string) string {
+	switch {
+	case line == "",
+		strings.HasPrefix(line, "<"),
+		strings.HasPrefix(line, "|"),
+		strings.HasPrefix(line, ".. "),
+		strings.HasPrefix(line, ":"),
+		strings.HasPrefix(line, "- "),
+		strings.HasPrefix(line, "* "),
+		strings.HasPrefix(line, "+ "),
+		orderedListMarker.MatchString(line),
+		markdownLinkDef.MatchString(line),
+		underlinePattern.MatchString(line):
+		return ""
+	}
+
+	line = strings.TrimSpace(strings.TrimPrefix(line, ">"))
+
+	// Badge rows consist only of (linked) images
+	withoutImages := strings.TrimSpace(markdownImage.ReplaceAllString(line, ""))
+	withoutImages = strings.TrimSpace(markdownLink.ReplaceAllString(withoutImages, "$1"))
+	if withoutImages == "" {
+		return ""
+	}
+
+	return stripMarkdown(line)
+}
+
+// stripMarkdown removes inline markup, keeping link text
+func stripMarkdown(text string) string {
+	text = markdownImage.ReplaceAllString(text, "")
+	text = markdownLink.ReplaceAllString(text, "$1")
+	text = markdownRefLink.ReplaceAllString(text, "$1")
+	text = htmlTag.ReplaceAllString(text, "")
+	text = markdownEmphasis.ReplaceAllString(text, "")
+	return strings.TrimSpace(text)
+}
+
+// normalizeMemo collapses whitespace and shortens long descriptions to their first
+// sentence, or to a word boundary when the sentence is still too long
+func normalizeMemo(memo string) string {
+	memo = strings.Join(strings.Fields(memo), " ")
+	if len(memo) <= maxMemoLength {
+		return memo
+	}
+
+	if end := strings.Index(memo, ". "); end > 0 && end+1 <= maxMemoLength {
+		return memo[:end+1]
+	}
+
+	// Back off to a rune boundary so multibyte text is not split
+	limit := maxMemoLength - 3
+	for limit > 0 && !utf8.RuneStart(memo[limit]) {
+		limit--
+	}
+	cut := strings.LastIndex(memo[:limit], " ")
+	if cut <= 0 {
+		cut = limit
+	}
+	return strings.TrimRight(memo[:cut], " ,;:") + "..."
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestGenerateMemoFromPath_ProjectMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected string
+	}{
+		{
+			name: "package.json",
+			files: map[string]string{
+				"package.json": `{"name": "app", "description": "A web application"}`,
+				"README.md":    "# App\n\nThe README description.\n",
+			},
+			expected: "A web application",
+		},
+		{
+			name: "Cargo.toml",
+			files: map[string]string{
+				"Cargo.toml": "[package]\nname = \"tool\"\ndescription = \"A fast command line tool\"\n",
+			},
+			expected: "A fast command line tool",
+		},
+		{
+			name: "pyproject.toml project",
+			files: map[string]string{
+				"pyproject.toml": "[project]\nname = \"lib\"\ndescription = \"A Python library\"\n",
+			},
+			expected: "A Python library",
+		},
+		{
+			name: "pyproject.toml poetry",
+			files: map[string]string{
+				"pyproject.toml": "[tool.poetry]\nname = \"lib\"\ndescription = \"A Poetry package\"\n",
+			},
+			expected: "A Poetry package",
+		},
+		{
+			name: "pom.xml",
+			files: map[string]string{
+				"pom.xml": `<project>
+  <parent><artifactId>parent</artifactId></parent>
+  <artifactId>service</artifactId>
+  <description>
+    A Java service
+  </description>
+</project>`,
+			},
+			expected: "A Java service",
+		},
+		{
+			name: "go.mod",
+			files: map[string]string{
+				"go.mod": "module github.com/example/project\n\ngo 1.22\n",
+			},
+			expected: "github.com/example/project",
+		},
+		{
+			name: "empty description falls back to README",
+			files: map[string]string{
+				"package.json": `{"name": "app"}`,
+				"README.md":    "# App\n\nThe README description.\n",
+			},
+			expected: "The README description.",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range test.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatalf("Failed to write %s: %v", name, err)
+				}
+			}
+
+			if got := generateMemoFromPath(dir); got != test.expected {
+				t.Errorf("Expected memo %q, got %q", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestGenerateMemoFromPath_MarkdownReadme(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name: "badges and HTML",
+			content: `<p align="center"><img src="logo.png" width="120"></p>
+
+# Project
+
+[![Build](https://ci.example.com/badge.svg)](https://ci.example.com) [![Go Report](https://goreport.example.com/badge.svg)](https://goreport.example.com)
+![License](https://img.shields.io/badge/license-MIT-blue.svg)
+
+<!--
+  Generated file, do not edit
+-->
+
+A **fast** tool for [syncing](docs/sync.md) repositories.
+`,
+			expected: "A fast tool for syncing repositories.",
+		},
+		{
+			name:     "code fence before prose",
+			content:  "# Install\n\n```sh\ngo install github.com/example/tool@latest\n```\n\nTool keeps your checkouts `up to date`.\n",
+			expected: "Tool keeps your checkouts up to date.",
+		},
+		{
+			name:     "multi-line paragraph",
+			content:  "Project\n=======\n\nThe first line of the paragraph\ncontinues on the next line.\n\nSecond paragraph.\n",
+			expected: "The first line of the paragraph continues on the next line.",
+		},
+		{
+			name:     "rst directives",
+			content:  "Project\n=======\n\n.. image:: https://ci.example.com/badge.svg\n   :target: https://ci.example.com\n\nA reStructuredText project.\n",
+			expected: "A reStructuredText project.",
+		},
+		{
+			name:     "heading when there is no prose",
+			content:  "# A Descriptive Project Title\n\n- item one\n- item two\n",
+			expected: "A Descriptive Project Title",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte(test.content), 0644); err != nil {
+				t.Fatalf("Failed to write README: %v", err)
+			}
+
+			if got := generateMemoFromPath(dir); got != test.expected {
+				t.Errorf("Expected memo %q, got %q", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestNormalizeMemo(t *testing.T) {
+	// 过长的描述截取第一句
+	long := "Short first sentence. " + strings.Repeat("More words follow here ", 10)
+	if got := normalizeMemo(long); got != "Short first sentence." {
+		t.Errorf("Expected first sentence, got %q", got)
+	}
+
+	// 没有句号时在单词边界截断
+	got := normalizeMemo(strings.Repeat("word ", 40))
+	if len(got) > maxMemoLength || !strings.HasSuffix(got, "word...") {
+		t.Errorf("Unexpected truncation: %q", got)
+	}
+
+	// 没有空格的多字节文本在字符边界截断
+	got = normalizeMemo("xy" + strings.Repeat("仓库", 60))
+	if len(got) > maxMemoLength || !utf8.ValidString(got) || !strings.HasSuffix(got, "仓...") {
+		t.Errorf("Unexpected multibyte truncation: %q", got)
+	}
+
+	if got := normalizeMemo("  spaced \n  out  "); got != "spaced out" {
+		t.Errorf("Expected collapsed whitespace, got %q", got)
+	}
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MergeResult describes how discovered repositories were reconciled with an existing configuration
+type MergeResult struct {
+	// Added lists repositories found on disk that were missing from the configuration
+	Added []string
+	// Kept counts configured repositories that were found on disk again
+	Kept int
+	// Missing lists configured repositories whose directory no longer exists
+	Missing []string
+	// Content is the merged configuration file
+	Content []byte
+}
+
+// Write saves the merged configuration file
+func (r *MergeResult) Write(configPath string) error {
+	return writeConfigContent(configPath, r.Content)
+}
+
+// MergeDiscovered reconciles a generated configuration with an existing configuration file.
+// New repositories are appended; configured repositories keep every user-set field, and
+// those whose directory, relative to the configuration file, no longer exists are reported
+// as missing but kept. TOML files are edited in place so comments and ordering are preserved.
+func MergeDiscovered(configPath string, discovered *Config) (*MergeResult, error) {
+	data, format, err := readConfigData(configPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := Parse(data, format)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MergeResult{}
+	baseDir := filepath.Dir(configPath)
+
+	for _, site := range cfg.Sites {
+		for _, repo := range site.Repos {
+			repoPath := repo.FullPath(site)
+			if !filepath.IsAbs(repoPath) {
+				repoPath = filepath.Join(baseDir, repoPath)
+			}
+			if _, err := os.Stat(repoPath); err != nil {
+				result.Missing = append(result.Missing, repoLabel(site.RemotePrefix, repo.Repo))
+			}
+		}
+	}
+
+	for _, site := range discovered.Sites {
+		for _, repo := range site.Repos {
+			if cfg.hasRepo(site.RemotePrefix, repo.Repo) {
+				result.Kept++
+				continue
+			}
+
+			siteIndex := cfg.findSite(site.RemotePrefix, site.Dir)
+			if format == FormatTOML {
+				newSite := site
+				newSite.Repos = nil
+				data, err = insertRepoTOML(data, cfg, siteIndex, newSite, repo)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			if siteIndex >= 0 {
+				cfg.Sites[siteIndex].Repos = append(cfg.Sites[siteIndex].Repos, repo)
+			} else {
+				newSite := site
+				newSite.Repos = []Repo{repo}
+				cfg.Sites = append(cfg.Sites, newSite)
+			}
+			result.Added = append(result.Added, repoLabel(site.RemotePrefix, repo.Repo))
+		}
+	}
+
+	if format == FormatTOML {
+		result.Content = data
+		return result, nil
+	}
+
+	result.Content, err = Marshal(cfg, format)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// repoLabel joins a remote prefix and repository name as RepoUrl does, without the .git suffix
+func repoLabel(remotePrefix, name string) string {
+	return strings.TrimSuffix(remotePrefix, "/") + "/" + name
+}
+
+// hasRepo reports whether a repository of the given remote is configured in any site
+func (cfg *Config) hasRepo(remotePrefix, name string) bool {
+	for _, site := range cfg.Sites {
+		if !sameRemotePrefix(site.RemotePrefix, remotePrefix) {
+			continue
+		}
+		for _, repo := range site.Repos {
+			if repo.Repo == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// findSite returns the index of the site with the given remote prefix and directory, or -1
+func (cfg *Config) findSite(remotePrefix, dir string) int {
+	for i, site := range cfg.Sites {
+		if sameRemotePrefix(site.RemotePrefix, remotePrefix) && filepath.Clean(site.Dir) == filepath.Clean(dir) {
+			return i
+		}
+	}
+	return -1
+}
+
+// sameRemotePrefix compares remote prefixes ignoring a trailing slash
+func sameRemotePrefix(a, b string) bool {
+	return strings.TrimSuffix(a, "/") == strings.TrimSuffix(b, "/")
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMergeDiscovered_TOML(t *testing.T) {
+	configFile := writeEditConfig(t, "repos.toml", "")
+	baseDir := filepath.Dir(configFile)
+	for _, dir := range []string{"github/go", "github/tools"} {
+		if err := os.MkdirAll(filepath.Join(baseDir, dir), 0755); err != nil {
+			t.Fatalf("Failed to create repo directory: %v", err)
+		}
+	}
+
+	existing := `# curated by hand
+[[sites]]
+    remote = "https://github.com/"
+    dir = "./github/"
+
+    [[sites.repos]]
+        repo = "golang/go"
+        memo = "my notes"
+        tags = ["lang"]
+        warm_up = true
+
+    [[sites.repos]]
+        repo = "old/gone"
+`
+	if err := os.WriteFile(configFile, []byte(existing), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	discovered := &Config{Sites: []SiteConfig{
+		{RemotePrefix: "https://github.com/", Dir: "./github/", Repos: []Repo{
+			{Repo: "golang/go", Memo: "The Go programming language"},
+			{Repo: "golang/tools"},
+		}},
+		{RemotePrefix: "https://gitlab.com/", Dir: "./gitlab/", Repos: []Repo{
+			{Repo: "group/project"},
+		}},
+	}}
+
+	result, err := MergeDiscovered(configFile, discovered)
+	if err != nil {
+		t.Fatalf("MergeDiscovered failed: %v", err)
+	}
+
+	if result.Kept != 1 {
+		t.Errorf("Expected 1 kept repository, got %d", result.Kept)
+	}
+	if strings.Join(result.Added, ",") != "https://github.com/golang/tools,https://gitlab.com/group/project" {
+		t.Errorf("Unexpected added repositories: %v", result.Added)
+	}
+	if strings.Join(result.Missing, ",") != "https://github.com/old/gone" {
+		t.Errorf("Unexpected missing repositories: %v", result.Missing)
+	}
+
+	// 用户手写的字段和注释保持不变，缺失的仓库也不会被删除
+	content := string(result.Content)
+	if !strings.HasPrefix(content, existing) {
+		t.Errorf("Existing content not preserved:\n%s", content)
+	}
+
+	cfg, err := Parse(result.Content, FormatTOML)
+	if err != nil {
+		t.Fatalf("Merged config does not parse: %v", err)
+	}
+	if len(cfg.Sites) != 2 {
+		t.Fatalf("Expected 2 sites, got %d", len(cfg.Sites))
+	}
+	if got := repoNames(cfg.Sites[0].Repos); strings.Join(got, ",") != "golang/go,old/gone,golang/tools" {
+		t.Errorf("Unexpected repos: %v", got)
+	}
+	if cfg.Sites[0].Repos[0].Memo != "my notes" || !cfg.Sites[0].Repos[0].WarmUp {
+		t.Errorf("User-set fields overwritten: %+v", cfg.Sites[0].Repos[0])
+	}
+	if cfg.Sites[1].Dir != "./gitlab/" || cfg.Sites[1].Repos[0].Repo != "group/project" {
+		t.Errorf("Unexpected new site: %+v", cfg.Sites[1])
+	}
+}
+
+func TestMergeDiscovered_JSON(t *testing.T) {
+	configFile := writeEditConfig(t, "repos.json", jsonConfigContent)
+
+	discovered := &Config{Sites: []SiteConfig{
+		{RemotePrefix: "https://github.com", Dir: "./repos", Repos: []Repo{
+			{Repo: "golang/example"},
+			{Repo: "golang/tools"},
+		}},
+	}}
+
+	result, err := MergeDiscovered(configFile, discovered)
+	if err != nil {
+		t.Fatalf("MergeDiscovered failed: %v", err)
+	}
+
+	cfg, err := Parse(result.Content, FormatJSON)
+	if err != nil {
+		t.Fatalf("Merged config does not parse: %v", err)
+	}
+	// 结尾斜杠不同的前缀和目录视为同一站点
+	if len(cfg.Sites) != 1 {
+		t.Fatalf("Expected 1 site, got %d", len(cfg.Sites))
+	}
+	if got := repoNames(cfg.Sites[0].Repos); strings.Join(got, ",") != "golang/example,golang/tools" {
+		t.Errorf("Unexpected repos: %v", got)
+	}
+	if cfg.Sites[0].Repos[0].Rename != "example-go" {
+		t.Errorf("User-set rename lost: %+v", cfg.Sites[0].Repos[0])
+	}
+}
+
+func TestMergeDiscovered_PathsRelativeToConfig(t *testing.T) {
+	existing := `[[sites]]
+    remote = "https://git.company.com"
+    dir = "./company/"
+
+    [[sites.repos]]
+        repo = "team/api"
+
+    [[sites.repos]]
+        repo = "team/gone"
+`
+	configFile := writeEditConfig(t, "repos.toml", existing)
+	if err := os.MkdirAll(filepath.Join(filepath.Dir(configFile), "company", "api"), 0755); err != nil {
+		t.Fatalf("Failed to create repo directory: %v", err)
+	}
+
+	// 目录相对于配置文件解析，与当前目录无关；前缀没有结尾斜杠时也正确拼接
+	result, err := MergeDiscovered(configFile, &Config{})
+	if err != nil {
+		t.Fatalf("MergeDiscovered failed: %v", err)
+	}
+	if strings.Join(result.Missing, ",") != "https://git.company.com/team/gone" {
+		t.Errorf("Unexpected missing repositories: %v", result.Missing)
+	}
+}
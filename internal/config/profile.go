@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Profile represents a named set of overrides applied on top of site and repo settings
+type Profile struct {
+	Depth  *int     `toml:"depth" yaml:"depth,omitempty" json:"depth,omitempty" desc:"Shallow clone depth applied to every repository (0 for full clones)" jsonschema:"minimum=0"`
+	WarmUp *bool    `toml:"warm_up" yaml:"warm_up,omitempty" json:"warm_up,omitempty" desc:"Force warm-up on or off for every repository"`
+	Update string   `toml:"update" yaml:"update,omitempty" json:"update,omitempty" desc:"Update strategy applied to every repository" jsonschema:"enum=pull|rebase|ff-only|fetch|skip"`
+	Tags   []string `toml:"tags" yaml:"tags,omitempty" json:"tags,omitempty" desc:"Only process repositories carrying one of these tags"`
+}
+
+// ProfileNames returns the sorted names of all profiles defined in the configuration
+func (cfg *Config) ProfileNames() []string {
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyProfile applies the named profile to every site and repository in the configuration.
+// Repositories not matching the profile's tags are removed.
+func (cfg *Config) ApplyProfile(name string) error {
+	profile, ok := cfg.Profiles[name]
+	if !ok {
+		available := cfg.ProfileNames()
+		if len(available) == 0 {
+			return fmt.Errorf("profile %q not found: no profiles defined", name)
+		}
+		return fmt.Errorf("profile %q not found (available: %s)", name, strings.Join(available, ", "))
+	}
+
+	for i := range cfg.Sites {
+		site := &cfg.Sites[i]
+		if profile.WarmUp != nil {
+			site.WarmUpAll = *profile.WarmUp
+		}
+
+		repos := make([]Repo, 0, len(site.Repos))
+		for _, repo := range site.Repos {
+			if !repo.Settings(*site).HasAnyTag(profile.Tags) {
+				continue
+			}
+			if profile.Depth != nil {
+				repo.Depth = profile.Depth
+			}
+			if profile.WarmUp != nil {
+				repo.WarmUp = *profile.WarmUp
+			}
+			if profile.Update != "" {
+				repo.Update = profile.Update
+			}
+			repos = append(repos, repo)
+		}
+		site.Repos = repos
+	}
+
+	return nil
+}
+
+// writeProfiles appends the profile tables to a TOML builder
+func writeProfiles(builder *strings.Builder, cfg *Config) {
+	for _, name := range cfg.ProfileNames() {
+		profile := cfg.Profiles[name]
+		builder.WriteString(fmt.Sprintf("[profiles.%s]\n", tomlKey(name)))
+		if profile.Depth != nil {
+			builder.WriteString(fmt.Sprintf("    depth = %d\n", *profile.Depth))
+		}
+		if profile.WarmUp != nil {
+			builder.WriteString(fmt.Sprintf("    warm_up = %t\n", *profile.WarmUp))
+		}
+		if profile.Update != "" {
+			builder.WriteString(fmt.Sprintf("    update = %q\n", profile.Update))
+		}
+		if len(profile.Tags) > 0 {
+			builder.WriteString(fmt.Sprintf("    tags = %s\n", tomlStringArray(profile.Tags)))
+		}
+		builder.WriteString("\n")
+	}
+}
+
+// tomlKey returns name as a bare TOML key when possible, quoting it otherwise
+func tomlKey(name string) string {
+	if name == "" {
+		return `""`
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return fmt.Sprintf("%q", name)
+		}
+	}
+	return name
+}
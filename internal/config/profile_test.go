@@ -0,0 +1,205 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const profileConfigContent = `[[sites]]
+remote = "https://github.com/"
+dir = "./repos/"
+warm_up_all = true
+
+[[sites.repos]]
+repo = "team/backend"
+warm_up = true
+tags = ["backend"]
+
+[[sites.repos]]
+repo = "team/frontend"
+update = "rebase"
+tags = ["frontend"]
+
+[profiles.ci]
+depth = 1
+warm_up = false
+update = "fetch"
+
+[profiles.backend]
+tags = ["backend"]
+`
+
+func loadProfileConfig(t *testing.T) *Config {
+	t.Helper()
+	configFile := filepath.Join(t.TempDir(), "profiles.toml")
+	if err := os.WriteFile(configFile, []byte(profileConfigContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config: %v", err)
+	}
+	cfg, err := ReadFromFile(configFile)
+	if err != nil {
+		t.Fatalf("ReadFromFile failed: %v", err)
+	}
+	return cfg
+}
+
+func TestReadFromFile_Profiles(t *testing.T) {
+	cfg := loadProfileConfig(t)
+
+	if len(cfg.Profiles) != 2 {
+		t.Fatalf("Expected 2 profiles, got %d", len(cfg.Profiles))
+	}
+
+	ci := cfg.Profiles["ci"]
+	if ci.Depth == nil || *ci.Depth != 1 {
+		t.Errorf("Expected ci depth 1, got %v", ci.Depth)
+	}
+	if ci.WarmUp == nil || *ci.WarmUp {
+		t.Error("Expected ci warm_up to be explicitly false")
+	}
+	if ci.Update != "fetch" {
+		t.Errorf("Expected ci update 'fetch', got %s", ci.Update)
+	}
+
+	if cfg.Profiles["backend"].WarmUp != nil {
+		t.Error("Expected backend warm_up to be unset")
+	}
+}
+
+func TestApplyProfile_Overrides(t *testing.T) {
+	cfg := loadProfileConfig(t)
+
+	if err := cfg.ApplyProfile("ci"); err != nil {
+		t.Fatalf("ApplyProfile failed: %v", err)
+	}
+
+	site := cfg.Sites[0]
+	if site.WarmUpAll {
+		t.Error("Expected warm_up_all to be disabled by profile")
+	}
+	if len(site.Repos) != 2 {
+		t.Fatalf("Expected 2 repos, got %d", len(site.Repos))
+	}
+	for _, repo := range site.Repos {
+		if repo.WarmUp {
+			t.Errorf("Expected warm_up disabled for %s", repo.Repo)
+		}
+		if repo.Depth == nil || *repo.Depth != 1 {
+			t.Errorf("Expected depth 1 for %s, got %v", repo.Repo, repo.Depth)
+		}
+		if repo.Update != "fetch" {
+			t.Errorf("Expected update 'fetch' for %s, got %s", repo.Repo, repo.Update)
+		}
+	}
+}
+
+func TestApplyProfile_TagSelection(t *testing.T) {
+	cfg := loadProfileConfig(t)
+
+	if err := cfg.ApplyProfile("backend"); err != nil {
+		t.Fatalf("ApplyProfile failed: %v", err)
+	}
+
+	site := cfg.Sites[0]
+	if len(site.Repos) != 1 || site.Repos[0].Repo != "team/backend" {
+		t.Fatalf("Expected only team/backend to be selected, got %+v", site.Repos)
+	}
+
+	// 未设置的字段应保留仓库自身配置
+	if !site.WarmUpAll || !site.Repos[0].WarmUp {
+		t.Error("Expected warm-up settings to be preserved")
+	}
+	if site.Repos[0].Depth != nil {
+		t.Errorf("Expected full clone, got depth %d", *site.Repos[0].Depth)
+	}
+}
+
+func TestApplyProfile_FullClone(t *testing.T) {
+	configContent := `[[sites]]
+remote = "https://github.com/"
+dir = "./repos/"
+
+[sites.defaults]
+depth = 1
+
+[[sites.repos]]
+repo = "team/api"
+depth = 3
+
+[profiles.release]
+depth = 0
+`
+	cfg, err := Parse([]byte(configContent), FormatTOML)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if err := cfg.ApplyProfile("release"); err != nil {
+		t.Fatalf("ApplyProfile failed: %v", err)
+	}
+
+	// depth = 0 的 profile 覆盖仓库和站点的浅克隆设置
+	site := cfg.Sites[0]
+	if depth := site.Repos[0].Settings(site).Depth; depth != 0 {
+		t.Errorf("Expected full clone from profile, got depth %d", depth)
+	}
+}
+
+func TestApplyProfile_Unknown(t *testing.T) {
+	cfg := loadProfileConfig(t)
+
+	err := cfg.ApplyProfile("laptop")
+	if err == nil {
+		t.Fatal("Expected error for unknown profile")
+	}
+	if !strings.Contains(err.Error(), "backend, ci") {
+		t.Errorf("Expected available profiles in error, got: %v", err)
+	}
+
+	empty := &Config{}
+	if err := empty.ApplyProfile("ci"); err == nil {
+		t.Error("Expected error when no profiles are defined")
+	}
+}
+
+func TestRepoSettings_HasAnyTag(t *testing.T) {
+	settings := RepoSettings{Tags: []string{"backend", "go"}}
+
+	tests := []struct {
+		tags     []string
+		expected bool
+	}{
+		{nil, true},
+		{[]string{"go"}, true},
+		{[]string{"frontend", "backend"}, true},
+		{[]string{"frontend"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := settings.HasAnyTag(tt.tags); got != tt.expected {
+			t.Errorf("HasAnyTag(%v) = %v, expected %v", tt.tags, got, tt.expected)
+		}
+	}
+}
+
+func TestToTOML_Profiles(t *testing.T) {
+	cfg := loadProfileConfig(t)
+
+	content, err := ToTOML(cfg)
+	if err != nil {
+		t.Fatalf("ToTOML failed: %v", err)
+	}
+
+	for _, expected := range []string{
+		"[profiles.backend]",
+		"[profiles.ci]",
+		"warm_up = false",
+		`update = "fetch"`,
+		`tags = ["backend"]`,
+		`update = "rebase"`,
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("Expected TOML to contain %q, got:\n%s", expected, content)
+		}
+	}
+}
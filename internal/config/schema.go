@@ -0,0 +1,293 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaDraft is the JSON Schema dialect of the generated schema
+const SchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// Schema is a JSON Schema document generated from the configuration structs.
+// Descriptions come from `desc` struct tags and constraints from `jsonschema` tags.
+type Schema struct {
+	Draft                string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+}
+
+// ValidationError describes a configuration value that does not match the schema
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+// Error implements the error interface
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// GenerateSchema builds the JSON Schema of the configuration file
+func GenerateSchema() *Schema {
+	schema := schemaForType(reflect.TypeOf(Config{}))
+	schema.Draft = SchemaDraft
+	schema.Title = "repoll configuration"
+	schema.Description = "Repositories managed by repoll"
+	// Allow editors to reference the schema from JSON configs
+	schema.Properties["$schema"] = &Schema{Type: "string", Description: "JSON Schema reference for editor integration"}
+	return schema
+}
+
+// schemaForType maps a Go type to its JSON Schema
+func schemaForType(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaForType(t.Elem())
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: schemaForType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaForType(t.Elem())}
+	case reflect.Struct:
+		schema := &Schema{
+			Type:                 "object",
+			Properties:           make(map[string]*Schema),
+			AdditionalProperties: false,
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+
+			property := schemaForType(field.Type)
+			property.Description = field.Tag.Get("desc")
+			applyConstraints(property, field.Tag.Get("jsonschema"))
+			if hasConstraint(field.Tag.Get("jsonschema"), "required") {
+				schema.Required = append(schema.Required, name)
+			}
+			schema.Properties[name] = property
+		}
+		return schema
+	default:
+		return &Schema{}
+	}
+}
+
+// applyConstraints copies enum and minimum constraints from a jsonschema tag
+func applyConstraints(schema *Schema, tag string) {
+	for _, part := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "enum":
+			schema.Enum = strings.Split(value, "|")
+		case "minimum":
+			if min, err := strconv.ParseFloat(value, 64); err == nil {
+				schema.Minimum = &min
+			}
+		}
+	}
+}
+
+// hasConstraint reports whether a jsonschema tag contains the given flag
+func hasConstraint(tag, flag string) bool {
+	for _, part := range strings.Split(tag, ",") {
+		if part == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// JSON renders the schema as indented JSON
+func (s *Schema) JSON() (string, error) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode schema: %w", err)
+	}
+	return string(data), nil
+}
+
+// Validate checks a decoded JSON value against the schema
+func (s *Schema) Validate(value interface{}) []ValidationError {
+	var errs []ValidationError
+	s.validate("$", value, &errs)
+	return errs
+}
+
+// validate appends the schema violations of value at path to errs
+func (s *Schema) validate(path string, value interface{}, errs *[]ValidationError) {
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			fail("expected object, got %s", jsonTypeName(value))
+			return
+		}
+		for _, name := range s.Required {
+			if _, ok := object[name]; !ok {
+				fail("missing required property %q", name)
+			}
+		}
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			childPath := path + "." + key
+			if property, ok := s.Properties[key]; ok {
+				property.validate(childPath, object[key], errs)
+				continue
+			}
+			switch additional := s.AdditionalProperties.(type) {
+			case *Schema:
+				additional.validate(childPath, object[key], errs)
+			case bool:
+				if !additional {
+					fail("unknown property %q", key)
+				}
+			}
+		}
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			fail("expected array, got %s", jsonTypeName(value))
+			return
+		}
+		if s.Items != nil {
+			for i, item := range array {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, errs)
+			}
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			fail("expected string, got %s", jsonTypeName(value))
+			return
+		}
+		// An empty string selects the default, as when loading the configuration
+		if len(s.Enum) > 0 && str != "" && !containsString(s.Enum, str) {
+			fail("invalid value %q (allowed: %s)", str, strings.Join(s.Enum, ", "))
+		}
+	case "integer":
+		number, ok := value.(float64)
+		if !ok || number != math.Trunc(number) {
+			fail("expected integer, got %s", jsonTypeName(value))
+			return
+		}
+		if s.Minimum != nil && number < *s.Minimum {
+			fail("must be >= %v", *s.Minimum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("expected boolean, got %s", jsonTypeName(value))
+		}
+	}
+}
+
+// jsonTypeName returns the JSON type name of a decoded value
+func jsonTypeName(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// containsString reports whether values contains s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks configuration data in the given format against the generated schema
+func Validate(data []byte, format string) ([]ValidationError, error) {
+	var raw interface{}
+
+	switch format {
+	case FormatTOML:
+		var table map[string]interface{}
+		if err := toml.Unmarshal(data, &table); err != nil {
+			return nil, fmt.Errorf("failed to parse TOML: %w", err)
+		}
+		raw = table
+	case FormatYAML:
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
+		if raw == nil {
+			raw = map[string]interface{}{}
+		}
+	case FormatJSON:
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config format: %s", format)
+	}
+
+	// Normalize numbers and nested tables to their JSON representation
+	normalized, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to normalize config: %w", err)
+	}
+	var value interface{}
+	if err := json.Unmarshal(normalized, &value); err != nil {
+		return nil, fmt.Errorf("failed to normalize config: %w", err)
+	}
+
+	return GenerateSchema().Validate(value), nil
+}
+
+// ValidateFile checks a configuration file against the generated schema
+func ValidateFile(configPath string) ([]ValidationError, error) {
+	data, format, err := readConfigData(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return Validate(data, format)
+}
@@ -0,0 +1,187 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/khicago/repoll/internal/git"
+)
+
+// walkSchema 遍历所有带名称的属性
+func walkSchema(t *testing.T, path string, schema *Schema, visit func(path string, s *Schema)) {
+	t.Helper()
+	for name, property := range schema.Properties {
+		childPath := path + "." + name
+		visit(childPath, property)
+		walkSchema(t, childPath, property, visit)
+	}
+	if schema.Items != nil {
+		walkSchema(t, path+"[]", schema.Items, visit)
+	}
+	if additional, ok := schema.AdditionalProperties.(*Schema); ok {
+		walkSchema(t, path+".*", additional, visit)
+	}
+}
+
+func TestGenerateSchema_Structure(t *testing.T) {
+	schema := GenerateSchema()
+
+	if schema.Draft != SchemaDraft || schema.Type != "object" {
+		t.Errorf("Unexpected root schema: %+v", schema)
+	}
+	if !reflect.DeepEqual(schema.Required, []string{"sites"}) {
+		t.Errorf("Expected sites to be required, got %v", schema.Required)
+	}
+
+	site := schema.Properties["sites"].Items
+	if !reflect.DeepEqual(site.Required, []string{"remote", "dir"}) {
+		t.Errorf("Expected remote and dir to be required, got %v", site.Required)
+	}
+	if site.AdditionalProperties != false {
+		t.Error("Expected unknown site properties to be rejected")
+	}
+
+	repo := site.Properties["repos"].Items
+	if repo.Properties["depth"].Type != "integer" || repo.Properties["warm_up"].Type != "boolean" {
+		t.Errorf("Unexpected repo property types: %+v", repo.Properties)
+	}
+
+	commands := repo.Properties["warm_up_commands"]
+	if commands.Type != "array" || commands.Items.Type != "array" || commands.Items.Items.Type != "string" {
+		t.Errorf("Expected warm_up_commands to be an array of string arrays, got %+v", commands)
+	}
+
+	profiles, ok := schema.Properties["profiles"].AdditionalProperties.(*Schema)
+	if !ok || profiles.Properties["warm_up"].Type != "boolean" {
+		t.Errorf("Expected profiles to map names to profile objects, got %+v", schema.Properties["profiles"])
+	}
+}
+
+func TestGenerateSchema_Descriptions(t *testing.T) {
+	// 每个配置项都必须有描述，保证文档与加载器一致
+	walkSchema(t, "$", GenerateSchema(), func(path string, s *Schema) {
+		if strings.HasSuffix(path, "[]") || strings.HasSuffix(path, ".*") {
+			return
+		}
+		if s.Description == "" {
+			t.Errorf("Missing description for %s", path)
+		}
+	})
+}
+
+func TestGenerateSchema_UpdateEnum(t *testing.T) {
+	expected := []string{git.UpdatePull, git.UpdateRebase, git.UpdateFFOnly, git.UpdateFetch, git.UpdateSkip}
+
+	walkSchema(t, "$", GenerateSchema(), func(path string, s *Schema) {
+		if strings.HasSuffix(path, ".update") && !reflect.DeepEqual(s.Enum, expected) {
+			t.Errorf("Update enum at %s = %v, expected %v", path, s.Enum, expected)
+		}
+	})
+}
+
+func TestSchema_JSON(t *testing.T) {
+	content, err := GenerateSchema().JSON()
+	if err != nil {
+		t.Fatalf("JSON failed: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(content), &decoded); err != nil {
+		t.Fatalf("Schema is not valid JSON: %v", err)
+	}
+	if decoded["$schema"] != SchemaDraft {
+		t.Errorf("Expected $schema %s, got %v", SchemaDraft, decoded["$schema"])
+	}
+}
+
+func TestValidate_ValidConfigs(t *testing.T) {
+	tests := map[string]string{
+		FormatTOML: profileConfigContent,
+		FormatYAML: yamlConfigContent,
+		FormatJSON: jsonConfigContent,
+	}
+
+	for format, content := range tests {
+		errs, err := Validate([]byte(content), format)
+		if err != nil {
+			t.Fatalf("%s: Validate failed: %v", format, err)
+		}
+		if len(errs) > 0 {
+			t.Errorf("%s: expected no validation errors, got %v", format, errs)
+		}
+	}
+}
+
+func TestValidate_InvalidConfig(t *testing.T) {
+	content := `[[sites]]
+remote_prefix = "https://github.com/"
+dir = 42
+
+[[sites.repos]]
+repo = "team/api"
+update = "merge"
+depth = -1
+warm_up = "yes"
+
+[profiles.ci]
+depth = 1.5
+`
+
+	errs, err := Validate([]byte(content), FormatTOML)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	expected := []string{
+		`$.sites[0]: missing required property "remote"`,
+		`$.sites[0].dir: expected string, got integer`,
+		`$.sites[0]: unknown property "remote_prefix"`,
+		`$.sites[0].repos[0].depth: must be >= 0`,
+		`$.sites[0].repos[0].update: invalid value "merge"`,
+		`$.sites[0].repos[0].warm_up: expected boolean, got string`,
+		`$.profiles.ci.depth: expected integer, got number`,
+	}
+
+	var messages []string
+	for _, e := range errs {
+		messages = append(messages, e.Error())
+	}
+	joined := strings.Join(messages, "\n")
+	for _, want := range expected {
+		if !strings.Contains(joined, want) {
+			t.Errorf("Expected validation error %q, got:\n%s", want, joined)
+		}
+	}
+}
+
+func TestValidate_EmptyEnumValue(t *testing.T) {
+	content := `[[sites]]
+remote = "https://github.com/"
+dir = "./"
+
+[[sites.repos]]
+repo = "team/api"
+update = ""
+warm_up_mode = ""
+`
+
+	// 空字符串与加载时一样表示使用默认值
+	errs, err := Validate([]byte(content), FormatTOML)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if len(errs) > 0 {
+		t.Errorf("Expected empty enum values to be valid, got %v", errs)
+	}
+}
+
+func TestValidate_ParseError(t *testing.T) {
+	if _, err := Validate([]byte("[[sites"), FormatTOML); err == nil {
+		t.Error("Expected parse error")
+	}
+	if _, err := Validate([]byte("{}"), "ini"); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+}
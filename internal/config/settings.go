@@ -0,0 +1,134 @@
+package config
+
+// SiteDefaults holds repository settings inherited by every repo of a site
+type SiteDefaults struct {
+	Branch         string       `toml:"branch" yaml:"branch,omitempty" json:"branch,omitempty" desc:"Branch or tag checked out when cloning"`
+	Depth          int          `toml:"depth" yaml:"depth,omitempty" json:"depth,omitempty" desc:"Shallow clone depth (0 for a full clone)" jsonschema:"minimum=0"`
+	Update         string       `toml:"update" yaml:"update,omitempty" json:"update,omitempty" desc:"Update strategy for existing clones" jsonschema:"enum=pull|rebase|ff-only|fetch|skip"`
+	WarmUpCommands [][]string   `toml:"warm_up_commands" yaml:"warm_up_commands,omitempty" json:"warm_up_commands,omitempty" desc:"Commands run instead of the auto-detected warm-up"`
+	WarmUpSteps    []WarmUpStep `toml:"warm_up_steps" yaml:"warm_up_steps,omitempty" json:"warm_up_steps,omitempty" desc:"Warm-up commands with their own directory, environment and timeout, run after warm_up_commands"`
+	WarmUpMode     string       `toml:"warm_up_mode" yaml:"warm_up_mode,omitempty" json:"warm_up_mode,omitempty" desc:"Whether custom warm-up commands replace or extend the auto-detected warm-up" jsonschema:"enum=replace|extend"`
+	WarmUpMutable  bool         `toml:"warm_up_mutable" yaml:"warm_up_mutable,omitempty" json:"warm_up_mutable,omitempty" desc:"Keep changes warm-up makes to tracked files instead of reverting them"`
+	Tags           []string     `toml:"tags" yaml:"tags,omitempty" json:"tags,omitempty" desc:"Labels used by profile tag selection"`
+	Hooks          *Hooks       `toml:"hooks" yaml:"hooks,omitempty" json:"hooks,omitempty" desc:"Commands run after git operations"`
+}
+
+// Warm-up modes controlling how custom commands combine with the auto-detected warm-up
+const (
+	WarmUpReplace = "replace"
+	WarmUpExtend  = "extend"
+)
+
+// WarmUpStep is a custom warm-up command with its own working directory, environment and timeout
+type WarmUpStep struct {
+	Run     []string          `toml:"run" yaml:"run" json:"run" desc:"Command and arguments" jsonschema:"required"`
+	Dir     string            `toml:"dir" yaml:"dir,omitempty" json:"dir,omitempty" desc:"Working directory, relative to the repository directory"`
+	Env     map[string]string `toml:"env" yaml:"env,omitempty" json:"env,omitempty" desc:"Environment variables added to the command"`
+	Timeout string            `toml:"timeout" yaml:"timeout,omitempty" json:"timeout,omitempty" desc:"Maximum run time, e.g. 5m (no limit when empty)"`
+}
+
+// Hooks holds commands run after repository git operations
+type Hooks struct {
+	PostClone  [][]string `toml:"post_clone" yaml:"post_clone,omitempty" json:"post_clone,omitempty" desc:"Commands run after a fresh clone"`
+	PostUpdate [][]string `toml:"post_update" yaml:"post_update,omitempty" json:"post_update,omitempty" desc:"Commands run after updating an existing clone"`
+}
+
+// IsEmpty reports whether no hook commands are configured
+func (h *Hooks) IsEmpty() bool {
+	return h == nil || len(h.PostClone) == 0 && len(h.PostUpdate) == 0
+}
+
+// RepoSettings holds the effective settings of a repository after inheriting site defaults
+type RepoSettings struct {
+	Branch         string
+	Depth          int
+	Update         string
+	WarmUp         bool
+	WarmUpCommands [][]string
+	WarmUpSteps    []WarmUpStep
+	WarmUpMode     string
+	WarmUpMutable  bool
+	Tags           []string
+	Hooks          Hooks
+}
+
+// Settings resolves the effective settings of the repository.
+// Values set on the repository override the site defaults, including zero values such as
+// depth = 0, branch = "" or warm_up_mutable = false.
+func (repo Repo) Settings(site SiteConfig) RepoSettings {
+	var defaults SiteDefaults
+	if site.Defaults != nil {
+		defaults = *site.Defaults
+	}
+	settings := RepoSettings{
+		Branch:         defaults.Branch,
+		Depth:          defaults.Depth,
+		Update:         defaults.Update,
+		WarmUp:         repo.WarmUp || site.WarmUpAll,
+		WarmUpCommands: defaults.WarmUpCommands,
+		WarmUpSteps:    defaults.WarmUpSteps,
+		WarmUpMode:     defaults.WarmUpMode,
+		WarmUpMutable:  defaults.WarmUpMutable,
+		Tags:           defaults.Tags,
+	}
+	if defaults.Hooks != nil {
+		settings.Hooks = *defaults.Hooks
+	}
+
+	if repo.Branch != nil {
+		settings.Branch = *repo.Branch
+	}
+	if repo.Depth != nil {
+		settings.Depth = *repo.Depth
+	}
+	if repo.Update != "" {
+		settings.Update = repo.Update
+	}
+	// Custom warm-up commands and steps are overridden together
+	if len(repo.WarmUpCommands) > 0 || len(repo.WarmUpSteps) > 0 {
+		settings.WarmUpCommands = repo.WarmUpCommands
+		settings.WarmUpSteps = repo.WarmUpSteps
+	}
+	if repo.WarmUpMode != "" {
+		settings.WarmUpMode = repo.WarmUpMode
+	}
+	if repo.WarmUpMutable != nil {
+		settings.WarmUpMutable = *repo.WarmUpMutable
+	}
+	if len(repo.Tags) > 0 {
+		settings.Tags = repo.Tags
+	}
+	if repo.Hooks != nil && len(repo.Hooks.PostClone) > 0 {
+		settings.Hooks.PostClone = repo.Hooks.PostClone
+	}
+	if repo.Hooks != nil && len(repo.Hooks.PostUpdate) > 0 {
+		settings.Hooks.PostUpdate = repo.Hooks.PostUpdate
+	}
+
+	return settings
+}
+
+// CustomWarmUp returns the custom warm-up steps in order, commands before steps
+func (s RepoSettings) CustomWarmUp() []WarmUpStep {
+	steps := make([]WarmUpStep, 0, len(s.WarmUpCommands)+len(s.WarmUpSteps))
+	for _, command := range s.WarmUpCommands {
+		steps = append(steps, WarmUpStep{Run: command})
+	}
+	return append(steps, s.WarmUpSteps...)
+}
+
+// HasAnyTag reports whether the repository carries at least one of the given tags.
+// An empty tag list matches every repository.
+func (s RepoSettings) HasAnyTag(tags []string) bool {
+	if len(tags) == 0 {
+		return true
+	}
+	for _, want := range tags {
+		for _, tag := range s.Tags {
+			if tag == want {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,278 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadFromFile_SiteDefaults(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "defaults.toml")
+	configContent := `[[sites]]
+remote = "https://github.com/"
+dir = "./repos/"
+
+[sites.defaults]
+branch = "develop"
+depth = 1
+update = "ff-only"
+warm_up_commands = [["make", "bootstrap"]]
+tags = ["team"]
+hooks = { post_clone = [["./scripts/setup.sh"]], post_update = [["make", "gen"]] }
+
+[[sites.repos]]
+repo = "team/api"
+
+[[sites.repos]]
+repo = "team/web"
+branch = "main"
+depth = 5
+update = "rebase"
+warm_up_commands = [["npm", "ci"]]
+tags = ["frontend"]
+hooks = { post_clone = [["npm", "run", "setup"]] }
+`
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config: %v", err)
+	}
+
+	cfg, err := ReadFromFile(configFile)
+	if err != nil {
+		t.Fatalf("ReadFromFile failed: %v", err)
+	}
+
+	site := cfg.Sites[0]
+	if site.Defaults.Branch != "develop" || site.Defaults.Depth != 1 || site.Defaults.Update != "ff-only" {
+		t.Errorf("Unexpected site defaults: %+v", site.Defaults)
+	}
+
+	// 未覆盖的仓库继承站点默认值
+	api := site.Repos[0].Settings(site)
+	expectedAPI := RepoSettings{
+		Branch:         "develop",
+		Depth:          1,
+		Update:         "ff-only",
+		WarmUpCommands: [][]string{{"make", "bootstrap"}},
+		Tags:           []string{"team"},
+		Hooks: Hooks{
+			PostClone:  [][]string{{"./scripts/setup.sh"}},
+			PostUpdate: [][]string{{"make", "gen"}},
+		},
+	}
+	if !reflect.DeepEqual(api, expectedAPI) {
+		t.Errorf("Unexpected inherited settings:\n got %+v\nwant %+v", api, expectedAPI)
+	}
+
+	// 仓库自身的配置覆盖默认值
+	web := site.Repos[1].Settings(site)
+	expectedWeb := RepoSettings{
+		Branch:         "main",
+		Depth:          5,
+		Update:         "rebase",
+		WarmUpCommands: [][]string{{"npm", "ci"}},
+		Tags:           []string{"frontend"},
+		Hooks: Hooks{
+			PostClone:  [][]string{{"npm", "run", "setup"}},
+			PostUpdate: [][]string{{"make", "gen"}},
+		},
+	}
+	if !reflect.DeepEqual(web, expectedWeb) {
+		t.Errorf("Unexpected overridden settings:\n got %+v\nwant %+v", web, expectedWeb)
+	}
+}
+
+func TestRepo_Settings_WarmUp(t *testing.T) {
+	tests := []struct {
+		repo     Repo
+		site     SiteConfig
+		expected bool
+	}{
+		{Repo{WarmUp: true}, SiteConfig{}, true},
+		{Repo{}, SiteConfig{WarmUpAll: true}, true},
+		{Repo{}, SiteConfig{}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.repo.Settings(tt.site).WarmUp; got != tt.expected {
+			t.Errorf("Settings().WarmUp = %v, expected %v", got, tt.expected)
+		}
+	}
+}
+
+func TestApplyProfile_InheritedTags(t *testing.T) {
+	cfg := &Config{
+		Sites: []SiteConfig{
+			{
+				RemotePrefix: "https://github.com/",
+				Dir:          "./backend/",
+				Defaults:     &SiteDefaults{Tags: []string{"backend"}},
+				Repos:        []Repo{{Repo: "team/api"}, {Repo: "team/docs", Tags: []string{"docs"}}},
+			},
+		},
+		Profiles: map[string]Profile{"backend": {Tags: []string{"backend"}}},
+	}
+
+	if err := cfg.ApplyProfile("backend"); err != nil {
+		t.Fatalf("ApplyProfile failed: %v", err)
+	}
+
+	repos := cfg.Sites[0].Repos
+	if len(repos) != 1 || repos[0].Repo != "team/api" {
+		t.Errorf("Expected only team/api to inherit the backend tag, got %+v", repos)
+	}
+}
+
+func TestRepo_Settings_ZeroOverrides(t *testing.T) {
+	configContent := `[[sites]]
+remote = "https://github.com/"
+dir = "./repos/"
+
+[sites.defaults]
+branch = "develop"
+depth = 1
+warm_up_mutable = true
+
+[[sites.repos]]
+repo = "team/api"
+
+[[sites.repos]]
+repo = "team/web"
+branch = ""
+depth = 0
+warm_up_mutable = false
+`
+	cfg, err := Parse([]byte(configContent), FormatTOML)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	site := cfg.Sites[0]
+
+	if api := site.Repos[0].Settings(site); api.Branch != "develop" || api.Depth != 1 || !api.WarmUpMutable {
+		t.Errorf("Expected inherited settings, got %+v", api)
+	}
+
+	// 显式设置的零值同样覆盖站点默认值：默认分支、完整克隆、还原预热修改
+	if web := site.Repos[1].Settings(site); web.Branch != "" || web.Depth != 0 || web.WarmUpMutable {
+		t.Errorf("Expected zero overrides, got %+v", web)
+	}
+
+	// 零值覆盖在生成的 TOML 中保留
+	content, err := ToTOML(cfg)
+	if err != nil {
+		t.Fatalf("ToTOML failed: %v", err)
+	}
+	reparsed, err := Parse([]byte(content), FormatTOML)
+	if err != nil {
+		t.Fatalf("Generated TOML does not parse: %v\n%s", err, content)
+	}
+	if web := reparsed.Sites[0].Repos[1].Settings(reparsed.Sites[0]); web.Branch != "" || web.Depth != 0 || web.WarmUpMutable {
+		t.Errorf("Expected zero overrides to survive ToTOML, got:\n%s", content)
+	}
+}
+
+func TestToTOML_SiteDefaults(t *testing.T) {
+	branch := "main"
+	cfg := &Config{
+		Sites: []SiteConfig{
+			{
+				RemotePrefix: "https://github.com/",
+				Dir:          "./repos/",
+				Defaults: &SiteDefaults{
+					Branch: "develop",
+					Depth:  1,
+					Hooks:  &Hooks{PostClone: [][]string{{"make", "setup"}}},
+				},
+				Repos: []Repo{
+					{Repo: "team/api", Branch: &branch, WarmUpCommands: [][]string{{"make", "bootstrap"}}},
+				},
+			},
+		},
+	}
+
+	content, err := ToTOML(cfg)
+	if err != nil {
+		t.Fatalf("ToTOML failed: %v", err)
+	}
+
+	for _, expected := range []string{
+		"[sites.defaults]",
+		`branch = "develop"`,
+		`hooks = { post_clone = [["make", "setup"]] }`,
+		`branch = "main"`,
+		`warm_up_commands = [["make", "bootstrap"]]`,
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("Expected TOML to contain %q, got:\n%s", expected, content)
+		}
+	}
+
+	if strings.Index(content, "[sites.defaults]") > strings.Index(content, "[[sites.repos]]") {
+		t.Error("Expected [sites.defaults] to precede the repository tables")
+	}
+}
+
+func TestRepo_Settings_WarmUpSteps(t *testing.T) {
+	configContent := `[[sites]]
+remote = "https://github.com/"
+dir = "./repos/"
+
+[sites.defaults]
+warm_up_commands = [["make", "bootstrap"]]
+warm_up_steps = [{ run = ["./scripts/setup.sh"], dir = "scripts", env = { CI = "1" }, timeout = "5m" }]
+warm_up_mode = "extend"
+
+[[sites.repos]]
+repo = "team/api"
+
+[[sites.repos]]
+repo = "team/web"
+warm_up_steps = [{ run = ["npm", "ci"], dir = "web" }]
+warm_up_mode = "replace"
+warm_up_mutable = true
+`
+	cfg, err := Parse([]byte(configContent), FormatTOML)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	site := cfg.Sites[0]
+
+	// 命令在前、步骤在后，统一转换为预热步骤
+	api := site.Repos[0].Settings(site)
+	expected := []WarmUpStep{
+		{Run: []string{"make", "bootstrap"}},
+		{Run: []string{"./scripts/setup.sh"}, Dir: "scripts", Env: map[string]string{"CI": "1"}, Timeout: "5m"},
+	}
+	if !reflect.DeepEqual(api.CustomWarmUp(), expected) || api.WarmUpMode != WarmUpExtend {
+		t.Errorf("Unexpected inherited warm-up: %+v (%s)", api.CustomWarmUp(), api.WarmUpMode)
+	}
+
+	// 仓库的预热步骤整体覆盖站点默认的命令和步骤
+	web := site.Repos[1].Settings(site)
+	expected = []WarmUpStep{{Run: []string{"npm", "ci"}, Dir: "web"}}
+	if !reflect.DeepEqual(web.CustomWarmUp(), expected) || web.WarmUpMode != WarmUpReplace {
+		t.Errorf("Unexpected overridden warm-up: %+v (%s)", web.CustomWarmUp(), web.WarmUpMode)
+	}
+
+	// 默认以只读方式预热，仓库可以单独允许修改跟踪文件
+	if api.WarmUpMutable || !web.WarmUpMutable {
+		t.Errorf("Unexpected warm_up_mutable: api=%v web=%v", api.WarmUpMutable, web.WarmUpMutable)
+	}
+
+	// 生成的 TOML 可以重新解析为相同的配置
+	content, err := ToTOML(cfg)
+	if err != nil {
+		t.Fatalf("ToTOML failed: %v", err)
+	}
+	if !strings.Contains(content, `warm_up_steps = [{ run = ["./scripts/setup.sh"], dir = "scripts", env = { "CI" = "1" }, timeout = "5m" }]`) {
+		t.Errorf("Unexpected warm_up_steps in TOML:\n%s", content)
+	}
+	reparsed, err := Parse([]byte(content), FormatTOML)
+	if err != nil {
+		t.Fatalf("Generated TOML does not parse: %v", err)
+	}
+	if !reflect.DeepEqual(reparsed.Sites[0].Defaults, site.Defaults) || !reflect.DeepEqual(reparsed.Sites[0].Repos, site.Repos) {
+		t.Errorf("Round trip changed the configuration:\n%s", content)
+	}
+}
@@ -0,0 +1,248 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/khicago/repoll/internal/git"
+)
+
+// IgnoreFileName is the file in the scanned directory listing paths mkconf skips
+const IgnoreFileName = ".repollignore"
+
+// skippedDirs are never searched for repositories
+var skippedDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// GenerateOptions controls how GenerateFromDirectory searches for repositories
+type GenerateOptions struct {
+	// Nested also searches inside repositories for nested repositories
+	Nested bool
+	// Exclude holds glob patterns of paths to skip, in addition to IgnoreFileName
+	Exclude []string
+	// MaxDepth limits how many directory levels below the target are searched (0 for no limit)
+	MaxDepth int
+	// Workers is the number of parallel repository inspections (0 for one per CPU)
+	Workers int
+	// RecordLinks records linked worktrees and submodule usage on the generated repositories
+	RecordLinks bool
+	// PinCommits records the exact commit of every repository
+	PinCommits bool
+	// Warn reports repositories that are skipped or cannot be inspected; nil writes to stderr
+	// so that configurations and reports written to stdout stay intact
+	Warn func(format string, args ...interface{})
+}
+
+// warn reports a discovery problem through Warn, or to stderr
+func (opts GenerateOptions) warn(format string, args ...interface{}) {
+	if opts.Warn != nil {
+		opts.Warn(format, args...)
+		return
+	}
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
+
+// discoveredRepo is the result of inspecting one repository directory
+type discoveredRepo struct {
+	path string
+	info *git.RepositoryInfo
+	err  error
+}
+
+// findRepositories walks targetDir and returns the repository directories in lexical order.
+// The walk stops at repository roots unless opts.Nested is set.
+func findRepositories(targetDir string, opts GenerateOptions) ([]string, error) {
+	patterns, err := readIgnoreFile(filepath.Join(targetDir, IgnoreFileName))
+	if err != nil {
+		return nil, err
+	}
+	patterns = append(patterns, opts.Exclude...)
+
+	var repos []string
+	err = filepath.WalkDir(targetDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(targetDir, p)
+		if err != nil {
+			return err
+		}
+		if rel != "." {
+			rel = filepath.ToSlash(rel)
+			if skippedDirs[d.Name()] || matchIgnore(patterns, rel) {
+				return filepath.SkipDir
+			}
+		}
+
+		depth := 0
+		if rel != "." {
+			depth = strings.Count(rel, "/") + 1
+		}
+
+		if git.IsBareRepository(p) {
+			repos = append(repos, p)
+			return filepath.SkipDir
+		}
+		if _, err := os.Stat(filepath.Join(p, ".git")); err == nil {
+			repos = append(repos, p)
+			if !opts.Nested {
+				return filepath.SkipDir
+			}
+		}
+
+		if opts.MaxDepth > 0 && depth >= opts.MaxDepth {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return repos, nil
+}
+
+// discoverRepositories inspects repository directories in parallel, keeping their order
+func discoverRepositories(paths []string, workers int) []discoveredRepo {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	results := make([]discoveredRepo, len(paths))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				info, err := git.DiscoverRepository(paths[i])
+				results[i] = discoveredRepo{path: paths[i], info: info, err: err}
+			}
+		}()
+	}
+
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// kindPriority ranks the checkouts sharing a repository; the lowest one represents it
+var kindPriority = map[string]int{
+	git.KindClone:    0,
+	git.KindWorktree: 1,
+	git.KindBare:     2,
+}
+
+// primaryCheckouts selects one checkout per repository: the regular clone if there is one,
+// otherwise the first linked worktree, otherwise the bare repository.
+// Submodule checkouts are never primary; they belong to their superproject.
+func primaryCheckouts(discovered []discoveredRepo) map[string]bool {
+	best := make(map[string]discoveredRepo)
+	for _, d := range discovered {
+		if d.err != nil || d.info.Kind == git.KindSubmodule {
+			continue
+		}
+		current, ok := best[d.info.CommonDir]
+		if !ok || kindPriority[d.info.Kind] < kindPriority[current.info.Kind] {
+			best[d.info.CommonDir] = d
+		}
+	}
+
+	primary := make(map[string]bool, len(best))
+	for _, d := range best {
+		primary[d.path] = true
+	}
+	return primary
+}
+
+// linkedWorktrees lists the worktrees of a repository other than repoPath,
+// with paths relative to repoPath
+func linkedWorktrees(repoPath string) []Worktree {
+	listed, err := git.ListWorktrees(repoPath)
+	if err != nil {
+		return nil
+	}
+
+	self := git.CanonicalPath(repoPath)
+	var worktrees []Worktree
+	for _, w := range listed {
+		if git.CanonicalPath(w.Path) == self {
+			continue
+		}
+		rel, err := filepath.Rel(self, git.CanonicalPath(w.Path))
+		if err != nil {
+			rel = w.Path
+		}
+		worktrees = append(worktrees, Worktree{Path: filepath.ToSlash(rel), Branch: w.Branch})
+	}
+	return worktrees
+}
+
+// readIgnoreFile reads glob patterns from an ignore file; a missing file yields no patterns
+func readIgnoreFile(filename string) ([]string, error) {
+	file, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	return patterns, nil
+}
+
+// matchIgnore reports whether a slash-separated path relative to the scanned directory
+// matches any pattern. Like .gitignore, patterns without a slash match any path component
+// and a leading slash anchors the pattern to the scanned directory.
+func matchIgnore(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(filepath.ToSlash(pattern), "/")
+		anchored := strings.HasPrefix(pattern, "/") || strings.HasPrefix(pattern, "./")
+		pattern = strings.TrimPrefix(strings.TrimPrefix(pattern, "./"), "/")
+		if pattern == "" {
+			continue
+		}
+
+		target := rel
+		if !anchored && !strings.Contains(pattern, "/") {
+			target = path.Base(rel)
+		}
+		if matched, _ := path.Match(pattern, target); matched {
+			return true
+		}
+	}
+	return false
+}
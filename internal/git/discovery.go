@@ -9,6 +9,18 @@ import (
 	"strings"
 )
 
+// Repository kinds reported by DiscoverRepository
+const (
+	// KindClone is a regular clone with its own .git directory
+	KindClone = "clone"
+	// KindWorktree is a linked worktree sharing the repository of another checkout
+	KindWorktree = "worktree"
+	// KindSubmodule is a submodule checkout inside a superproject
+	KindSubmodule = "submodule"
+	// KindBare is a bare repository without a working tree
+	KindBare = "bare"
+)
+
 // RepositoryInfo holds information about a discovered Git repository
 type RepositoryInfo struct {
 	Path        string
@@ -16,6 +28,19 @@ type RepositoryInfo struct {
 	HasOrigin   bool
 	Uncommitted bool
 	Unmerged    bool
+	// Kind classifies the checkout as KindClone, KindWorktree, KindSubmodule or KindBare
+	Kind string
+	// CommonDir is the absolute repository directory shared by a clone and its worktrees
+	CommonDir string
+	// Superproject is the working tree containing a submodule checkout
+	Superproject string
+}
+
+// WorktreeInfo describes a working tree attached to a repository
+type WorktreeInfo struct {
+	Path string
+	// Branch is the checked out branch, or the commit for a detached worktree
+	Branch string
 }
 
 // DiscoverRepository discovers Git repository information from a directory path
@@ -24,12 +49,16 @@ func DiscoverRepository(path string) (*RepositoryInfo, error) {
 		Path: path,
 	}
 
-	// Check if it's a Git repository
+	// Check if it's a Git repository; linked worktrees and submodules have a .git file
 	gitDir := filepath.Join(path, ".git")
-	if _, err := os.Stat(gitDir); os.IsNotExist(err) {
+	if _, err := os.Stat(gitDir); os.IsNotExist(err) && !IsBareRepository(path) {
 		return nil, fmt.Errorf("not a Git repository: %s", path)
 	}
 
+	if err := classifyRepository(info); err != nil {
+		return nil, fmt.Errorf("not a Git repository: %s: %w", path, err)
+	}
+
 	// Get origin URL
 	origin, err := getGitOrigin(path)
 	if err == nil && origin != "" {
@@ -52,6 +81,99 @@ func DiscoverRepository(path string) (*RepositoryInfo, error) {
 	return info, nil
 }
 
+// classifyRepository fills in the kind, common directory and superproject of a repository
+func classifyRepository(info *RepositoryInfo) error {
+	cmd := exec.Command("git", "rev-parse", "--is-bare-repository", "--absolute-git-dir", "--git-common-dir", "--show-superproject-working-tree")
+	cmd.Dir = info.Path
+	output, err := cmd.Output()
+	if err != nil {
+		return err
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) < 3 {
+		return fmt.Errorf("unexpected rev-parse output: %q", string(output))
+	}
+
+	absoluteGitDir := lines[1]
+	info.CommonDir = lines[2]
+	if !filepath.IsAbs(info.CommonDir) {
+		info.CommonDir = filepath.Join(info.Path, info.CommonDir)
+	}
+	info.CommonDir = CanonicalPath(info.CommonDir)
+
+	switch {
+	case lines[0] == "true":
+		info.Kind = KindBare
+	case len(lines) > 3 && lines[3] != "":
+		info.Kind = KindSubmodule
+		info.Superproject = lines[3]
+	case CanonicalPath(absoluteGitDir) != info.CommonDir:
+		info.Kind = KindWorktree
+	default:
+		info.Kind = KindClone
+	}
+	return nil
+}
+
+// ListWorktrees returns the linked worktrees of a repository, excluding its main working tree
+func ListWorktrees(repoDir string) ([]WorktreeInfo, error) {
+	cmd := exec.Command("git", "worktree", "list", "--porcelain")
+	cmd.Dir = repoDir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git worktree list failed: %w", err)
+	}
+
+	var worktrees []WorktreeInfo
+	for i, block := range strings.Split(strings.TrimSpace(string(output)), "\n\n") {
+		var worktree WorktreeInfo
+		bare := false
+		for _, line := range strings.Split(block, "\n") {
+			key, value, _ := strings.Cut(line, " ")
+			switch key {
+			case "worktree":
+				worktree.Path = value
+			case "HEAD":
+				if worktree.Branch == "" {
+					worktree.Branch = value
+				}
+			case "branch":
+				worktree.Branch = strings.TrimPrefix(value, "refs/heads/")
+			case "bare":
+				bare = true
+			}
+		}
+		// The first entry is the main working tree (or the bare repository itself)
+		if i == 0 || bare || worktree.Path == "" {
+			continue
+		}
+		worktrees = append(worktrees, worktree)
+	}
+	return worktrees, nil
+}
+
+// IsBareRepository reports whether a directory looks like a bare Git repository
+func IsBareRepository(dir string) bool {
+	for _, name := range []string{"HEAD", "objects", "refs"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// CanonicalPath resolves symlinks so paths reported by Git can be compared
+func CanonicalPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	return path
+}
+
 // getGitOrigin gets the origin URL of a Git repository
 func getGitOrigin(repoPath string) (string, error) {
 	cmd := exec.Command("git", "remote", "get-url", "origin")
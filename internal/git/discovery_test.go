@@ -481,4 +481,77 @@ func TestExtractRepoNameFromURL_EdgeCases(t *testing.T) {
 			}
 		})
 	}
-} 
\ No newline at end of file
+} 
+func TestDiscoverRepository_Kinds(t *testing.T) {
+	upstream := newUpstreamRepo(t, 1)
+	workspace := t.TempDir()
+
+	clone := filepath.Join(workspace, "clone")
+	runGit(t, workspace, "clone", upstream, clone)
+	runGit(t, clone, "worktree", "add", "-b", "feature", "../feature")
+	runGit(t, clone, "-c", "protocol.file.allow=always", "submodule", "add", upstream, "lib")
+
+	bare := filepath.Join(workspace, "mirror.git")
+	runGit(t, workspace, "clone", "--bare", upstream, bare)
+
+	tests := []struct {
+		path string
+		kind string
+	}{
+		{clone, KindClone},
+		{filepath.Join(workspace, "feature"), KindWorktree},
+		{filepath.Join(clone, "lib"), KindSubmodule},
+		{bare, KindBare},
+	}
+
+	for _, tt := range tests {
+		info, err := DiscoverRepository(tt.path)
+		if err != nil {
+			t.Fatalf("DiscoverRepository(%s) failed: %v", tt.path, err)
+		}
+		if info.Kind != tt.kind {
+			t.Errorf("Expected %s to be a %s, got %s", tt.path, tt.kind, info.Kind)
+		}
+	}
+
+	// 主克隆与其链接的工作树共享同一个仓库目录
+	main, _ := DiscoverRepository(clone)
+	worktree, _ := DiscoverRepository(filepath.Join(workspace, "feature"))
+	if main.CommonDir != worktree.CommonDir {
+		t.Errorf("Expected shared common dir, got %s and %s", main.CommonDir, worktree.CommonDir)
+	}
+
+	submodule, _ := DiscoverRepository(filepath.Join(clone, "lib"))
+	if CanonicalPath(submodule.Superproject) != CanonicalPath(clone) {
+		t.Errorf("Expected superproject %s, got %s", clone, submodule.Superproject)
+	}
+}
+
+func TestListWorktrees(t *testing.T) {
+	upstream := newUpstreamRepo(t, 1)
+	workspace := t.TempDir()
+
+	clone := filepath.Join(workspace, "clone")
+	runGit(t, workspace, "clone", upstream, clone)
+	runGit(t, clone, "worktree", "add", "-b", "feature", "../feature")
+	runGit(t, clone, "worktree", "add", "--detach", "../detached")
+
+	worktrees, err := ListWorktrees(clone)
+	if err != nil {
+		t.Fatalf("ListWorktrees failed: %v", err)
+	}
+	if len(worktrees) != 2 {
+		t.Fatalf("Expected 2 linked worktrees, got %+v", worktrees)
+	}
+	branches := make(map[string]string)
+	for _, worktree := range worktrees {
+		branches[filepath.Base(worktree.Path)] = worktree.Branch
+	}
+	if branches["feature"] != "feature" {
+		t.Errorf("Unexpected worktrees: %+v", worktrees)
+	}
+	// 分离头指针的工作树记录提交哈希
+	if len(branches["detached"]) != 40 {
+		t.Errorf("Expected commit for detached worktree, got %+v", worktrees)
+	}
+}
@@ -5,18 +5,55 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
+// Update strategies supported by UpdateWithStrategy
+const (
+	UpdatePull   = "pull"
+	UpdateRebase = "rebase"
+	UpdateFFOnly = "ff-only"
+	UpdateFetch  = "fetch"
+	UpdateSkip   = "skip"
+)
+
+// CloneOptions controls how a repository is cloned
+type CloneOptions struct {
+	// Depth creates a shallow clone with the given history depth (0 = full clone)
+	Depth int
+	// Branch checks out the given branch or tag instead of the remote HEAD
+	Branch string
+	// RecurseSubmodules also clones the repository's submodules
+	RecurseSubmodules bool
+}
+
 // Clone clones a Git repository from URL to target directory
 func Clone(url, targetDir string) error {
+	return CloneWithOptions(url, targetDir, CloneOptions{})
+}
+
+// CloneWithOptions clones a Git repository from URL to target directory using the given options
+func CloneWithOptions(url, targetDir string, opts CloneOptions) error {
 	// Ensure parent directory exists
 	parentDir := filepath.Dir(targetDir)
 	if err := os.MkdirAll(parentDir, 0755); err != nil {
 		return fmt.Errorf("failed to create parent directory: %w", err)
 	}
 
-	cmd := exec.Command("git", "clone", url, targetDir)
+	args := []string{"clone"}
+	if opts.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(opts.Depth))
+	}
+	if opts.Branch != "" {
+		args = append(args, "--branch", opts.Branch)
+	}
+	if opts.RecurseSubmodules {
+		args = append(args, "--recurse-submodules")
+	}
+	args = append(args, url, targetDir)
+
+	cmd := exec.Command("git", args...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("git clone failed: %w\nOutput: %s", err, string(output))
@@ -25,13 +62,46 @@ func Clone(url, targetDir string) error {
 	return nil
 }
 
+// UpdateOptions controls how an existing repository is updated
+type UpdateOptions struct {
+	// Strategy is one of the Update* strategies (empty = UpdatePull)
+	Strategy string
+	// Branch is the configured branch or tag; a detached clone is checked out at the tag instead of pulled
+	Branch string
+}
+
 // Update updates an existing Git repository by pulling latest changes
 func Update(repoDir string) error {
+	return UpdateWithStrategy(repoDir, UpdatePull)
+}
+
+// UpdateWithStrategy updates an existing Git repository using the given update strategy.
+// An empty strategy is treated as UpdatePull.
+func UpdateWithStrategy(repoDir, strategy string) error {
+	return UpdateWithOptions(repoDir, UpdateOptions{Strategy: strategy})
+}
+
+// UpdateWithOptions updates an existing Git repository using the given options.
+// A detached HEAD is never pulled: it only follows a configured tag.
+func UpdateWithOptions(repoDir string, opts UpdateOptions) error {
 	// Check if it's a valid Git repository
 	if !isGitRepository(repoDir) {
 		return fmt.Errorf("not a valid Git repository: %s", repoDir)
 	}
 
+	pullArgs := []string{"pull"}
+	switch opts.Strategy {
+	case "", UpdatePull, UpdateFetch:
+	case UpdateRebase:
+		pullArgs = append(pullArgs, "--rebase")
+	case UpdateFFOnly:
+		pullArgs = append(pullArgs, "--ff-only")
+	case UpdateSkip:
+		return nil
+	default:
+		return fmt.Errorf("unknown update strategy: %s", opts.Strategy)
+	}
+
 	// Fetch latest changes
 	fetchCmd := exec.Command("git", "fetch", "origin")
 	fetchCmd.Dir = repoDir
@@ -39,6 +109,10 @@ func Update(repoDir string) error {
 		return fmt.Errorf("git fetch failed: %w\nOutput: %s", err, string(output))
 	}
 
+	if opts.Strategy == UpdateFetch {
+		return nil
+	}
+
 	// Get current branch
 	branchCmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
 	branchCmd.Dir = repoDir
@@ -48,8 +122,17 @@ func Update(repoDir string) error {
 	}
 	currentBranch := strings.TrimSpace(string(branchOutput))
 
+	// A detached HEAD has no branch to pull; a clone of the configured tag stays on the tag
+	if currentBranch == "HEAD" {
+		if opts.Branch != "" && isTag(repoDir, opts.Branch) {
+			return CheckoutCommit(repoDir, "refs/tags/"+opts.Branch)
+		}
+		return nil
+	}
+
 	// Pull changes
-	pullCmd := exec.Command("git", "pull", "origin", currentBranch)
+	pullArgs = append(pullArgs, "origin", currentBranch)
+	pullCmd := exec.Command("git", pullArgs...)
 	pullCmd.Dir = repoDir
 	if output, err := pullCmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("git pull failed: %w\nOutput: %s", err, string(output))
@@ -58,6 +141,50 @@ func Update(repoDir string) error {
 	return nil
 }
 
+// isTag reports whether name is a tag of the repository
+func isTag(repoDir, name string) bool {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", "refs/tags/"+name)
+	cmd.Dir = repoDir
+	return cmd.Run() == nil
+}
+
+// IsDetached reports whether HEAD is detached, as after checking out a tag or a commit
+func IsDetached(repoDir string) (bool, error) {
+	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	cmd.Dir = repoDir
+	output, err := cmd.Output()
+	if err != nil {
+		return false, fmt.Errorf("failed to get current branch: %w", err)
+	}
+	return strings.TrimSpace(string(output)) == "HEAD", nil
+}
+
+// UpdateSubmodules initializes and updates the submodules of a repository
+func UpdateSubmodules(repoDir string) error {
+	cmd := exec.Command("git", "submodule", "update", "--init", "--recursive")
+	cmd.Dir = repoDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git submodule update failed: %w\nOutput: %s", err, string(output))
+	}
+	return nil
+}
+
+// AddWorktree creates a linked worktree at path (relative to repoDir) checking out branch.
+// A branch that only exists on origin is created locally to track it.
+func AddWorktree(repoDir, path, branch string) error {
+	args := []string{"worktree", "add", path}
+	if branch != "" {
+		args = append(args, branch)
+	}
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git worktree add failed: %w\nOutput: %s", err, string(output))
+	}
+	return nil
+}
+
 // isGitRepository checks if a directory is a Git repository
 func isGitRepository(dir string) bool {
 	gitDir := filepath.Join(dir, ".git")
@@ -67,7 +194,8 @@ func isGitRepository(dir string) bool {
 	return false
 }
 
-// GetCurrentBranch returns the current branch name
+// GetCurrentBranch returns the current branch name.
+// With a detached HEAD it returns the tag pointing at HEAD, or the commit when there is none.
 func GetCurrentBranch(repoDir string) (string, error) {
 	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
 	cmd.Dir = repoDir
@@ -75,9 +203,115 @@ func GetCurrentBranch(repoDir string) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("failed to get current branch: %w", err)
 	}
+
+	branch := strings.TrimSpace(string(output))
+	if branch != "HEAD" {
+		return branch, nil
+	}
+
+	tagCmd := exec.Command("git", "describe", "--tags", "--exact-match", "HEAD")
+	tagCmd.Dir = repoDir
+	if tagOutput, err := tagCmd.Output(); err == nil {
+		return strings.TrimSpace(string(tagOutput)), nil
+	}
+	return GetHeadCommit(repoDir)
+}
+
+// GetHeadCommit returns the full SHA of the commit checked out in a repository
+func GetHeadCommit(repoDir string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = repoDir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD commit: %w", err)
+	}
 	return strings.TrimSpace(string(output)), nil
 }
 
+// GetGitDir returns the absolute git directory of a repository; linked worktrees have their own
+func GetGitDir(repoDir string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--absolute-git-dir")
+	cmd.Dir = repoDir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get git directory: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ChangedTrackedFiles lists the tracked files whose content differs from HEAD, relative to the repository root
+func ChangedTrackedFiles(repoDir string) ([]string, error) {
+	cmd := exec.Command("git", "diff", "--name-only", "-z", "HEAD")
+	cmd.Dir = repoDir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changed files: %w", err)
+	}
+
+	var files []string
+	for _, name := range strings.Split(string(output), "\x00") {
+		if name != "" {
+			files = append(files, name)
+		}
+	}
+	return files, nil
+}
+
+// RestoreFiles resets the given tracked files, relative to the repository root, to their content at HEAD
+func RestoreFiles(repoDir string, files []string) error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	args := append([]string{"checkout", "HEAD", "--"}, files...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git checkout failed: %w\nOutput: %s", err, string(output))
+	}
+	return nil
+}
+
+// IsCommitHash reports whether ref is a full hexadecimal commit SHA
+func IsCommitHash(ref string) bool {
+	if len(ref) != 40 && len(ref) != 64 {
+		return false
+	}
+	for _, c := range ref {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckoutCommit detaches HEAD at commit, fetching it from origin when it is not available locally
+func CheckoutCommit(repoDir, commit string) error {
+	if head, err := GetHeadCommit(repoDir); err == nil && head == commit {
+		return nil
+	}
+
+	checkout := func() ([]byte, error) {
+		cmd := exec.Command("git", "checkout", "--detach", commit)
+		cmd.Dir = repoDir
+		return cmd.CombinedOutput()
+	}
+
+	if _, err := checkout(); err == nil {
+		return nil
+	}
+
+	fetchCmd := exec.Command("git", "fetch", "origin", commit)
+	fetchCmd.Dir = repoDir
+	if output, err := fetchCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git fetch %s failed: %w\nOutput: %s", commit, err, string(output))
+	}
+	if output, err := checkout(); err != nil {
+		return fmt.Errorf("git checkout %s failed: %w\nOutput: %s", commit, err, string(output))
+	}
+	return nil
+}
+
 // GetRemoteURL returns the remote URL for the specified remote (default: origin)
 func GetRemoteURL(repoDir, remote string) (string, error) {
 	if remote == "" {
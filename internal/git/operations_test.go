@@ -2,7 +2,9 @@ package git
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -327,4 +329,260 @@ func TestUpdate_NestedGitRepository(t *testing.T) {
 	if err != nil {
 		t.Logf("Update failed as expected for nested repo: %v", err)
 	}
-} 
\ No newline at end of file
+} 
+// runGit 在指定目录执行 git 命令，失败时终止测试
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	args = append([]string{"-c", "user.name=repoll", "-c", "user.email=repoll@example.com", "-c", "init.defaultBranch=main"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// newUpstreamRepo 创建一个带有若干提交的本地仓库，用作克隆源
+func newUpstreamRepo(t *testing.T, commits int) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("Git not available, skipping test")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init")
+	for i := 0; i < commits; i++ {
+		name := filepath.Join(dir, "file.txt")
+		if err := os.WriteFile(name, []byte(strings.Repeat("x", i+1)), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		runGit(t, dir, "add", ".")
+		runGit(t, dir, "commit", "-m", "commit")
+	}
+	return dir
+}
+
+func TestCloneWithOptions_Depth(t *testing.T) {
+	upstream := newUpstreamRepo(t, 3)
+	target := filepath.Join(t.TempDir(), "shallow")
+
+	// 本地路径需要使用 file:// 协议，--depth 才会生效
+	err := CloneWithOptions("file://"+upstream, target, CloneOptions{Depth: 1})
+	if err != nil {
+		t.Fatalf("CloneWithOptions failed: %v", err)
+	}
+
+	count := runGit(t, target, "rev-list", "--count", "HEAD")
+	if count != "1" {
+		t.Errorf("Expected 1 commit in shallow clone, got %s", count)
+	}
+}
+
+func TestCloneWithOptions_FullClone(t *testing.T) {
+	upstream := newUpstreamRepo(t, 3)
+	target := filepath.Join(t.TempDir(), "full")
+
+	err := CloneWithOptions("file://"+upstream, target, CloneOptions{})
+	if err != nil {
+		t.Fatalf("CloneWithOptions failed: %v", err)
+	}
+
+	count := runGit(t, target, "rev-list", "--count", "HEAD")
+	if count != "3" {
+		t.Errorf("Expected 3 commits in full clone, got %s", count)
+	}
+}
+
+func TestUpdateWithStrategy(t *testing.T) {
+	tests := []struct {
+		strategy   string
+		expectPull bool
+	}{
+		{strategy: "", expectPull: true},
+		{strategy: UpdatePull, expectPull: true},
+		{strategy: UpdateRebase, expectPull: true},
+		{strategy: UpdateFFOnly, expectPull: true},
+		{strategy: UpdateFetch, expectPull: false},
+		{strategy: UpdateSkip, expectPull: false},
+	}
+
+	for _, tt := range tests {
+		t.Run("strategy_"+tt.strategy, func(t *testing.T) {
+			upstream := newUpstreamRepo(t, 1)
+			target := filepath.Join(t.TempDir(), "clone")
+			if err := Clone(upstream, target); err != nil {
+				t.Fatalf("Clone failed: %v", err)
+			}
+
+			// 上游新增提交
+			if err := os.WriteFile(filepath.Join(upstream, "new.txt"), []byte("new"), 0644); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+			runGit(t, upstream, "add", ".")
+			runGit(t, upstream, "commit", "-m", "second")
+
+			if err := UpdateWithStrategy(target, tt.strategy); err != nil {
+				t.Fatalf("UpdateWithStrategy(%q) failed: %v", tt.strategy, err)
+			}
+
+			_, err := os.Stat(filepath.Join(target, "new.txt"))
+			if tt.expectPull && err != nil {
+				t.Errorf("Expected new commit to be pulled with strategy %q", tt.strategy)
+			}
+			if !tt.expectPull && err == nil {
+				t.Errorf("Expected working tree untouched with strategy %q", tt.strategy)
+			}
+		})
+	}
+}
+
+func TestUpdateWithStrategy_Unknown(t *testing.T) {
+	upstream := newUpstreamRepo(t, 1)
+
+	err := UpdateWithStrategy(upstream, "merge-everything")
+	if err == nil || !strings.Contains(err.Error(), "unknown update strategy") {
+		t.Errorf("Expected unknown update strategy error, got: %v", err)
+	}
+}
+
+func TestUpdateWithOptions_Detached(t *testing.T) {
+	upstream := newUpstreamRepo(t, 1)
+	target := filepath.Join(t.TempDir(), "clone")
+	if err := Clone(upstream, target); err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+	runGit(t, target, "checkout", "--detach", "HEAD")
+	before, _ := GetHeadCommit(target)
+
+	runGit(t, upstream, "commit", "--allow-empty", "-m", "second")
+
+	// 分离头指针不会被拉取
+	if err := UpdateWithOptions(target, UpdateOptions{Strategy: UpdatePull}); err != nil {
+		t.Fatalf("UpdateWithOptions failed: %v", err)
+	}
+	if detached, err := IsDetached(target); err != nil || !detached {
+		t.Errorf("Expected HEAD to stay detached (%v)", err)
+	}
+	if head, _ := GetHeadCommit(target); head != before {
+		t.Errorf("Expected HEAD to stay at %s, got %s", before, head)
+	}
+}
+
+func TestGetCurrentBranch_Detached(t *testing.T) {
+	repo := newUpstreamRepo(t, 2)
+
+	if branch, err := GetCurrentBranch(repo); err != nil || branch != "main" {
+		t.Errorf("Expected branch main, got %q (%v)", branch, err)
+	}
+
+	// 分离头指针且有标签时返回标签
+	runGit(t, repo, "tag", "v1.0.0")
+	runGit(t, repo, "checkout", "--detach", "v1.0.0")
+	if branch, err := GetCurrentBranch(repo); err != nil || branch != "v1.0.0" {
+		t.Errorf("Expected tag v1.0.0, got %q (%v)", branch, err)
+	}
+
+	// 没有标签时返回提交哈希
+	runGit(t, repo, "checkout", "--detach", "HEAD~1")
+	branch, err := GetCurrentBranch(repo)
+	if err != nil || !IsCommitHash(branch) {
+		t.Errorf("Expected commit hash, got %q (%v)", branch, err)
+	}
+	if head, _ := GetHeadCommit(repo); head != branch {
+		t.Errorf("Expected HEAD commit %s, got %s", head, branch)
+	}
+}
+
+func TestCheckoutCommit(t *testing.T) {
+	upstream := newUpstreamRepo(t, 3)
+	first := runGit(t, upstream, "rev-list", "--max-parents=0", "HEAD")
+
+	// 浅克隆中不存在的提交需要先从 origin 获取
+	target := filepath.Join(t.TempDir(), "pinned")
+	if err := CloneWithOptions("file://"+upstream, target, CloneOptions{Depth: 1}); err != nil {
+		t.Fatalf("CloneWithOptions failed: %v", err)
+	}
+	if err := CheckoutCommit(target, first); err != nil {
+		t.Fatalf("CheckoutCommit failed: %v", err)
+	}
+	if head, _ := GetHeadCommit(target); head != first {
+		t.Errorf("Expected HEAD at %s, got %s", first, head)
+	}
+
+	// 已在目标提交时不做任何操作
+	if err := CheckoutCommit(target, first); err != nil {
+		t.Errorf("CheckoutCommit on current commit failed: %v", err)
+	}
+
+	if err := CheckoutCommit(target, strings.Repeat("0", 40)); err == nil {
+		t.Error("Expected error for unknown commit")
+	}
+}
+
+func TestIsCommitHash(t *testing.T) {
+	tests := map[string]bool{
+		strings.Repeat("a", 40): true,
+		strings.Repeat("b", 64): true,
+		"main":                  false,
+		"v1.0.0":                false,
+		strings.Repeat("g", 40): false,
+		"abc123":                false,
+	}
+	for ref, expected := range tests {
+		if got := IsCommitHash(ref); got != expected {
+			t.Errorf("IsCommitHash(%q) = %v, want %v", ref, got, expected)
+		}
+	}
+}
+
+func TestChangedTrackedFiles_RestoreFiles(t *testing.T) {
+	repo := newUpstreamRepo(t, 1)
+
+	files, err := ChangedTrackedFiles(repo)
+	if err != nil || len(files) != 0 {
+		t.Fatalf("Expected a clean worktree, got %v (%v)", files, err)
+	}
+
+	// 未跟踪的文件不计入，修改的跟踪文件需要列出
+	if err := os.WriteFile(filepath.Join(repo, "file.txt"), []byte("changed"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "untracked.txt"), []byte("new"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	files, err = ChangedTrackedFiles(repo)
+	if err != nil || !reflect.DeepEqual(files, []string{"file.txt"}) {
+		t.Fatalf("Expected [file.txt], got %v (%v)", files, err)
+	}
+
+	if err := RestoreFiles(repo, files); err != nil {
+		t.Fatalf("RestoreFiles failed: %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(repo, "file.txt")); string(content) != "x" {
+		t.Errorf("Expected restored content, got %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "untracked.txt")); err != nil {
+		t.Errorf("Untracked file should be kept: %v", err)
+	}
+}
+
+func TestGetGitDir(t *testing.T) {
+	repo := newUpstreamRepo(t, 1)
+
+	gitDir, err := GetGitDir(repo)
+	if err != nil {
+		t.Fatalf("GetGitDir failed: %v", err)
+	}
+	if CanonicalPath(gitDir) != CanonicalPath(filepath.Join(repo, ".git")) {
+		t.Errorf("Expected %s/.git, got %s", repo, gitDir)
+	}
+
+	// 链接工作树有自己的 git 目录
+	worktree := filepath.Join(t.TempDir(), "feature")
+	runGit(t, repo, "worktree", "add", "-b", "feature", worktree)
+	worktreeGitDir, err := GetGitDir(worktree)
+	if err != nil || worktreeGitDir == gitDir {
+		t.Errorf("Expected a separate git directory for the worktree, got %q (%v)", worktreeGitDir, err)
+	}
+}
@@ -0,0 +1,151 @@
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/khicago/repoll/internal/cli"
+	"github.com/khicago/repoll/internal/config"
+	"github.com/khicago/repoll/internal/reporter"
+)
+
+// Default concurrency of the pipeline stages: repositories are processed one at a time
+// unless --jobs or --warm-up-jobs ask for more
+const (
+	DefaultFetchJobs  = 1
+	DefaultWarmUpJobs = 1
+)
+
+// repoTask is a repository moving through the fetch and warm-up stages
+type repoTask struct {
+	repo       config.Repo
+	site       config.SiteConfig
+	settings   config.RepoSettings
+	targetPath string
+	action     *reporter.MakeAction
+	// elapsed is the time spent in the stages, excluding waiting for a warm-up worker
+	elapsed time.Duration
+}
+
+// pipeline clones or updates repositories in a fetch stage and hands those that need a
+// warm-up to a separate warm-up stage, so git operations and dependency installs overlap
+type pipeline struct {
+	opts        *ProcessorOptions
+	progressBar *cli.ProgressBar
+
+	mu       sync.Mutex
+	finished int
+	// paths serializes tasks sharing a target directory from fetch until they finish
+	paths map[string]*sync.Mutex
+}
+
+// runPipeline processes tasks with opts.FetchJobs fetch workers and opts.WarmUpJobs warm-up workers
+func runPipeline(tasks []*repoTask, opts *ProcessorOptions, progressBar *cli.ProgressBar) {
+	p := &pipeline{opts: opts, progressBar: progressBar, paths: make(map[string]*sync.Mutex)}
+
+	fetchQueue := make(chan *repoTask)
+	// Buffered so that fetch workers never wait for a slow warm-up
+	warmUpQueue := make(chan *repoTask, len(tasks))
+
+	var fetchers, warmers sync.WaitGroup
+	for i := 0; i < jobs(opts.FetchJobs, DefaultFetchJobs); i++ {
+		fetchers.Add(1)
+		go func() {
+			defer fetchers.Done()
+			for task := range fetchQueue {
+				if p.fetch(task) {
+					warmUpQueue <- task
+				}
+			}
+		}()
+	}
+	for i := 0; i < jobs(opts.WarmUpJobs, DefaultWarmUpJobs); i++ {
+		warmers.Add(1)
+		go func() {
+			defer warmers.Done()
+			for task := range warmUpQueue {
+				p.warmUp(task)
+			}
+		}()
+	}
+
+	for _, task := range tasks {
+		fetchQueue <- task
+	}
+	close(fetchQueue)
+	fetchers.Wait()
+	close(warmUpQueue)
+	warmers.Wait()
+}
+
+// jobs returns the configured number of workers, or fallback when it is not positive
+func jobs(configured, fallback int) int {
+	if configured > 0 {
+		return configured
+	}
+	return fallback
+}
+
+// fetch clones or updates the repository of a task and reports whether it still needs a warm-up
+func (p *pipeline) fetch(task *repoTask) bool {
+	p.lockPath(task.targetPath)
+
+	actionName, kind := "Cloning", reporter.ActionClone
+	if _, err := os.Stat(task.targetPath); err == nil {
+		actionName, kind = "Updating", reporter.ActionUpdate
+	}
+	task.action.Kind = kind
+	p.opts.UI.ProcessingRepo(task.action.Repository, actionName)
+
+	task.action.Time = time.Now()
+	err := fetchRepository(task.repo, task.site, p.opts)
+	task.elapsed = time.Since(task.action.Time)
+
+	if err != nil || !task.settings.WarmUp {
+		p.finish(task, err)
+		return false
+	}
+	return true
+}
+
+// warmUp warms up the repository of a fetched task
+func (p *pipeline) warmUp(task *repoTask) {
+	start := time.Now()
+	warmUpIfNeeded(task.targetPath, task.settings, p.opts, task.action)
+	task.elapsed += time.Since(start)
+	p.finish(task, nil)
+}
+
+// finish records the result of a task and releases its target directory
+func (p *pipeline) finish(task *repoTask, err error) {
+	action := task.action
+	action.Duration = task.elapsed
+	action.Success = err == nil
+	if err != nil {
+		action.Error = err.Error()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.opts.UI.RepoResult(action.Repository, action.Success, action.Duration, err)
+	p.finished++
+	if p.progressBar != nil {
+		p.progressBar.Update(p.finished)
+	}
+	p.paths[filepath.Clean(task.targetPath)].Unlock()
+}
+
+// lockPath waits until no other task works in the directory
+func (p *pipeline) lockPath(path string) {
+	path = filepath.Clean(path)
+	p.mu.Lock()
+	lock, ok := p.paths[path]
+	if !ok {
+		lock = &sync.Mutex{}
+		p.paths[path] = lock
+	}
+	p.mu.Unlock()
+	lock.Lock()
+}
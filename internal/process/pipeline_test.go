@@ -0,0 +1,88 @@
+package process
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/khicago/repoll/internal/config"
+	"github.com/khicago/repoll/internal/reporter"
+)
+
+func TestProcessSites_WarmUpOverlapsFetch(t *testing.T) {
+	workDir := t.TempDir()
+	nextClone := filepath.Join(workDir, "next", ".git")
+
+	// 第一个仓库的预热等待第二个仓库克隆完成，只有两个阶段并行时才能成功
+	sites := []config.SiteConfig{
+		{
+			RemotePrefix: newLocalUpstream(t, "slow"),
+			Dir:          workDir,
+			Repos: []config.Repo{{
+				Repo:           "slow",
+				WarmUp:         true,
+				WarmUpCommands: [][]string{{"sh", "-c", "for i in $(seq 100); do [ -d " + nextClone + " ] && exit 0; sleep 0.1; done; exit 1"}},
+			}},
+		},
+		{
+			RemotePrefix: newLocalUpstream(t, "next"),
+			Dir:          workDir,
+			Repos:        []config.Repo{{Repo: "next"}},
+		},
+	}
+
+	opts := testOptions()
+	opts.FetchJobs = 1
+	opts.WarmUpJobs = 1
+	report := &reporter.MakeReport{}
+	processSites(sites, report, opts, nil)
+
+	// 报告按配置顺序记录
+	if len(report.Actions) != 2 || report.Actions[0].Repository != "slow" || report.Actions[1].Repository != "next" {
+		t.Fatalf("Unexpected report actions: %+v", report.Actions)
+	}
+	slow := report.Actions[0]
+	if !slow.Success || len(slow.WarmUp) != 1 || !slow.WarmUp[0].Success {
+		t.Errorf("Expected the warm-up to overlap the next clone, got %+v", slow.WarmUp)
+	}
+	if !report.Actions[1].Success {
+		t.Errorf("Expected the second repository to succeed: %s", report.Actions[1].Error)
+	}
+}
+
+func TestProcessSites_SameTargetPath(t *testing.T) {
+	remote := newLocalUpstream(t, "service")
+	workDir := t.TempDir()
+
+	// 指向同一目录的仓库依次处理：先克隆，再更新
+	site := config.SiteConfig{
+		RemotePrefix: remote,
+		Dir:          workDir,
+		Repos:        []config.Repo{{Repo: "service"}, {Repo: "service"}, {Repo: "service"}},
+	}
+	repo := site.Repos[0]
+
+	report := &reporter.MakeReport{}
+	processSites([]config.SiteConfig{site}, report, testOptions(), nil)
+
+	for i, action := range report.Actions {
+		if !action.Success {
+			t.Errorf("Action %d failed: %s", i, action.Error)
+		}
+		kind := reporter.ActionUpdate
+		if i == 0 {
+			kind = reporter.ActionClone
+		}
+		if action.Kind != kind || action.Path != repo.FullPath(site) {
+			t.Errorf("Action %d: expected %s of %s, got %s of %s", i, kind, repo.FullPath(site), action.Kind, action.Path)
+		}
+	}
+}
+
+func TestJobs(t *testing.T) {
+	if got := jobs(0, DefaultFetchJobs); got != DefaultFetchJobs {
+		t.Errorf("Expected default jobs, got %d", got)
+	}
+	if got := jobs(3, DefaultFetchJobs); got != 3 {
+		t.Errorf("Expected configured jobs, got %d", got)
+	}
+}
@@ -1,8 +1,13 @@
 package process
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/khicago/repoll/internal/cli"
@@ -12,10 +17,22 @@ import (
 	"github.com/khicago/repoll/internal/warmup"
 )
 
+// warmUpStateFile records the last successful warm-up inside the repository's git directory
+const warmUpStateFile = "warmup.json"
+
 // ProcessorOptions contains options for the processor
 type ProcessorOptions struct {
-	UI     *cli.UIManager
-	DryRun bool
+	UI      *cli.UIManager
+	DryRun  bool
+	Profile string
+	// ConfigDir resolves relative expansion sources; defaults to the current directory
+	ConfigDir string
+	// ForceWarmUp warms up repositories even when their dependency manifests are unchanged
+	ForceWarmUp bool
+	// FetchJobs is the number of repositories cloned or updated in parallel (0 for DefaultFetchJobs)
+	FetchJobs int
+	// WarmUpJobs is the number of repositories warmed up in parallel (0 for DefaultWarmUpJobs)
+	WarmUpJobs int
 }
 
 // ProcessConfig processes a configuration file and manages repositories
@@ -32,6 +49,44 @@ func ProcessConfig(configPath string, report *reporter.MakeReport, opts *Process
 	if err != nil {
 		return fmt.Errorf("failed to read config: %w", err)
 	}
+
+	if opts.ConfigDir == "" && configPath != config.StdinPath {
+		opts.ConfigDir = filepath.Dir(configPath)
+	}
+
+	return Process(cfg, report, opts)
+}
+
+// Process manages the repositories of an already loaded configuration.
+// The selected profile, if any, is applied to cfg in place.
+func Process(cfg *config.Config, report *reporter.MakeReport, opts *ProcessorOptions) error {
+	if opts == nil {
+		opts = &ProcessorOptions{
+			UI: cli.NewUIManager(false, false),
+		}
+	}
+
+	expansions, err := cfg.ExpandSites(opts.ConfigDir)
+	if err != nil {
+		return fmt.Errorf("failed to expand sites: %w", err)
+	}
+	for _, expansion := range expansions {
+		if opts.DryRun {
+			opts.UI.DryRun("Expanded %d repositories for %s from %s", len(expansion.Repos), expansion.Site, expansion.Source)
+			for _, name := range expansion.Repos {
+				opts.UI.DryRun("  + %s", name)
+			}
+		} else {
+			opts.UI.Verbose("Expanded %d repositories for %s from %s", len(expansion.Repos), expansion.Site, expansion.Source)
+		}
+	}
+
+	if opts.Profile != "" {
+		if err := cfg.ApplyProfile(opts.Profile); err != nil {
+			return fmt.Errorf("failed to apply profile: %w", err)
+		}
+		opts.UI.Info("Using profile %s", opts.Profile)
+	}
 	
 	totalRepos := 0
 	for _, site := range cfg.Sites {
@@ -45,16 +100,7 @@ func ProcessConfig(configPath string, report *reporter.MakeReport, opts *Process
 		progressBar = opts.UI.NewProgressBar(totalRepos, "Processing")
 	}
 	
-	processedCount := 0
-	for _, site := range cfg.Sites {
-		opts.UI.Verbose("Processing site: %s", site.RemotePrefix)
-		
-		err := processSite(site, report, opts, progressBar, &processedCount)
-		if err != nil {
-			opts.UI.Error("Error processing site %s: %v", site.RemotePrefix, err)
-			continue
-		}
-	}
+	processSites(cfg.Sites, report, opts, progressBar)
 	
 	if progressBar != nil {
 		progressBar.Finish()
@@ -63,63 +109,84 @@ func ProcessConfig(configPath string, report *reporter.MakeReport, opts *Process
 	return nil
 }
 
-// processSite processes all repositories in a site configuration
-func processSite(site config.SiteConfig, report *reporter.MakeReport, opts *ProcessorOptions, progressBar *cli.ProgressBar, processedCount *int) error {
-	for _, repo := range site.Repos {
-		startTime := time.Now()
-		*processedCount++
-		
-		if progressBar != nil {
-			progressBar.Update(*processedCount)
-		}
-		
-		action := &reporter.MakeAction{
-			Time:       startTime,
-			Repository: repo.DisplayName(),
-			Memo:       repo.Memo,
-		}
-
-		// Determine the action to perform
-		targetPath := repo.FullPath(site)
-		actionName := "Cloning"
-		
-		if _, err := os.Stat(targetPath); err == nil {
-			actionName = "Updating"
-		}
-		
-		if opts.DryRun {
-			opts.UI.DryRun("Would %s %s -> %s", actionName, repo.DisplayName(), targetPath)
-			if shouldWarmUp(repo, site) {
-				opts.UI.DryRun("Would warm up %s", targetPath)
+// processSites processes the repositories of all sites through the fetch and warm-up pipeline,
+// recording their actions on report in configuration order
+func processSites(sites []config.SiteConfig, report *reporter.MakeReport, opts *ProcessorOptions, progressBar *cli.ProgressBar) {
+	var tasks []*repoTask
+	for _, site := range sites {
+		opts.UI.Verbose("Processing site: %s", site.RemotePrefix)
+		for _, repo := range site.Repos {
+			targetPath := repo.FullPath(site)
+			if opts.DryRun {
+				dryRunRepository(repo, site, targetPath, opts)
+				continue
 			}
-			continue
+			tasks = append(tasks, &repoTask{
+				repo:       repo,
+				site:       site,
+				settings:   repo.Settings(site),
+				targetPath: targetPath,
+				action: &reporter.MakeAction{
+					Repository: repo.DisplayName(),
+					Memo:       repo.Memo,
+					Site:       site.RemotePrefix,
+					Path:       targetPath,
+				},
+			})
 		}
-		
-		opts.UI.ProcessingRepo(repo.DisplayName(), actionName)
-		
-		err := processRepository(repo, site, opts)
-		duration := time.Since(startTime)
-		
-		action.Duration = duration
-		if err != nil {
-			action.Success = false
-			action.Error = err.Error()
-			opts.UI.RepoResult(repo.DisplayName(), false, duration, err)
-		} else {
-			action.Success = true
-			opts.UI.RepoResult(repo.DisplayName(), true, duration, nil)
+	}
+
+	if needsWarmUp(tasks) {
+		resolveToolchains(opts)
+	}
+	runPipeline(tasks, opts, progressBar)
+
+	if report != nil {
+		for _, task := range tasks {
+			report.Actions = append(report.Actions, task.action)
 		}
+	}
+}
 
-		if report != nil {
-			report.Actions = append(report.Actions, action)
+// needsWarmUp reports whether any of the tasks is warmed up
+func needsWarmUp(tasks []*repoTask) bool {
+	for _, task := range tasks {
+		if task.settings.WarmUp {
+			return true
 		}
 	}
+	return false
+}
 
-	return nil
+// resolveToolchains checks the warm-up toolchains once before any repository is processed
+func resolveToolchains(opts *ProcessorOptions) {
+	for _, err := range warmup.ResolveToolchains() {
+		opts.UI.Verbose("Toolchain unavailable: %v", err)
+	}
+}
+
+// dryRunRepository prints what processing a repository would do
+func dryRunRepository(repo config.Repo, site config.SiteConfig, targetPath string, opts *ProcessorOptions) {
+	actionName := "Cloning"
+	if _, err := os.Stat(targetPath); err == nil {
+		actionName = "Updating"
+	}
+
+	opts.UI.DryRun("Would %s %s -> %s", actionName, repo.DisplayName(), targetPath)
+	for _, worktree := range repo.Worktrees {
+		opts.UI.DryRun("Would create worktree %s", filepath.Join(targetPath, worktree.Path))
+	}
+	if shouldWarmUp(repo, site) {
+		opts.UI.DryRun("Would warm up %s", targetPath)
+		for _, step := range repo.Settings(site).CustomWarmUp() {
+			opts.UI.DryRun("Would run warm-up step: %s", strings.Join(step.Run, " "))
+		}
+	}
 }
 
-// processRepository processes a single repository
-func processRepository(repo config.Repo, site config.SiteConfig, opts *ProcessorOptions) error {
+// fetchRepository clones or updates a repository, then runs its hooks and creates its worktrees
+func fetchRepository(repo config.Repo, site config.SiteConfig, opts *ProcessorOptions) error {
+	settings := repo.Settings(site)
 	targetPath := repo.FullPath(site)
 	repoURL := repo.RepoUrl(site)
 
@@ -127,35 +194,284 @@ func processRepository(repo config.Repo, site config.SiteConfig, opts *Processor
 	if _, err := os.Stat(targetPath); err == nil {
 		// Repository exists, try to update it
 		opts.UI.Verbose("Repository exists at %s, updating...", targetPath)
-		err := git.Update(targetPath)
+		var err error
+		if repo.Commit != "" && settings.Update != git.UpdateSkip {
+			// Pinned repositories move to their commit instead of following a branch
+			err = git.CheckoutCommit(targetPath, repo.Commit)
+		} else {
+			err = git.UpdateWithOptions(targetPath, git.UpdateOptions{Strategy: settings.Update, Branch: settings.Branch})
+		}
 		if err != nil {
 			return fmt.Errorf("failed to update repository: %w", err)
 		}
+		if repo.Submodules && settings.Update != git.UpdateSkip && settings.Update != git.UpdateFetch {
+			if err := git.UpdateSubmodules(targetPath); err != nil {
+				return err
+			}
+		}
+		if err := runHooks(targetPath, "post_update", settings.Hooks.PostUpdate, opts); err != nil {
+			return err
+		}
 	} else {
 		// Repository doesn't exist, clone it
 		opts.UI.Verbose("Cloning %s to %s", repoURL, targetPath)
-		err := git.Clone(repoURL, targetPath)
+		err := git.CloneWithOptions(repoURL, targetPath, git.CloneOptions{
+			Depth:             settings.Depth,
+			Branch:            settings.Branch,
+			RecurseSubmodules: repo.Submodules,
+		})
 		if err != nil {
 			return fmt.Errorf("failed to clone repository: %w", err)
 		}
+		if repo.Commit != "" {
+			if err := git.CheckoutCommit(targetPath, repo.Commit); err != nil {
+				return fmt.Errorf("failed to check out pinned commit: %w", err)
+			}
+		}
+		if err := runHooks(targetPath, "post_clone", settings.Hooks.PostClone, opts); err != nil {
+			return err
+		}
 	}
 
-	// Perform warm-up if needed
-	if shouldWarmUp(repo, site) {
-		opts.UI.Verbose("Starting warm-up for %s", targetPath)
-		err := warmup.Perform(targetPath)
+	return ensureWorktrees(targetPath, repo.Worktrees, opts)
+}
+
+// warmUpIfNeeded warms up a repository unless its fingerprint is unchanged, reverting the
+// tracked files it modifies unless the warm-up is mutable. Failures are reported as
+// warnings because they do not make the repository unusable.
+func warmUpIfNeeded(targetPath string, settings config.RepoSettings, opts *ProcessorOptions, action *reporter.MakeAction) {
+	fingerprint, statePath := warmUpFingerprint(targetPath, settings, opts)
+	if reason := warmUpSkipReason(fingerprint, statePath, opts); reason != "" {
+		opts.UI.Verbose("Skipping warm-up for %s: %s", targetPath, reason)
+		if action != nil {
+			action.WarmUpSkipped = reason
+		}
+		return
+	}
+
+	// Tracked files already modified before the warm-up are local edits and are left alone
+	var dirty map[string]bool
+	if !settings.WarmUpMutable {
+		dirty = changedTrackedFiles(targetPath, opts)
+	}
+
+	opts.UI.Verbose("Starting warm-up for %s", targetPath)
+	results, err := warmUpRepository(targetPath, settings, opts)
+	if action != nil {
+		action.WarmUp = append(action.WarmUp, results...)
+	}
+	if dirty != nil {
+		reverted := revertWarmUpChanges(targetPath, dirty, opts)
+		if action != nil {
+			action.WarmUpReverted = reverted
+		}
+	}
+	if err != nil {
+		opts.UI.Warning("Warm-up failed for %s: %v", targetPath, err)
+		// Don't return error for warm-up failures as they're not critical
+	} else {
+		opts.UI.Verbose("Warm-up completed for %s", targetPath)
+		// A skipped step has to run once its toolchain is installed. The fingerprint is taken
+		// again because the warm-up creates install directories and may rewrite lockfiles.
+		if statePath != "" && !anySkipped(results) {
+			if fingerprint, _ := warmUpFingerprint(targetPath, settings, opts); fingerprint != "" {
+				if err := warmup.WriteState(statePath, warmup.State{Fingerprint: fingerprint, Time: time.Now()}); err != nil {
+					opts.UI.Verbose("Could not record warm-up state: %v", err)
+				}
+			}
+		}
+	}
+}
+
+// warmUpFingerprint returns the warm-up fingerprint of a repository and the path of its state file
+// inside the git directory; either is empty when it cannot be determined
+func warmUpFingerprint(repoDir string, settings config.RepoSettings, opts *ProcessorOptions) (string, string) {
+	fingerprint, err := warmup.Fingerprint(repoDir, fmt.Sprintf("%s %v", settings.WarmUpMode, settings.CustomWarmUp()))
+	if err != nil {
+		opts.UI.Verbose("Could not fingerprint %s: %v", repoDir, err)
+		return "", ""
+	}
+	gitDir, err := git.GetGitDir(repoDir)
+	if err != nil {
+		return fingerprint, ""
+	}
+	return fingerprint, filepath.Join(gitDir, "repoll", warmUpStateFile)
+}
+
+// warmUpSkipReason explains why the warm-up can be skipped, or returns "" when it has to run
+func warmUpSkipReason(fingerprint, statePath string, opts *ProcessorOptions) string {
+	if opts.ForceWarmUp || fingerprint == "" || statePath == "" {
+		return ""
+	}
+	state, err := warmup.ReadState(statePath)
+	if err != nil {
+		opts.UI.Verbose("Ignoring warm-up state: %v", err)
+		return ""
+	}
+	if state == nil || state.Fingerprint != fingerprint {
+		return ""
+	}
+	return fmt.Sprintf("dependency manifests and tool versions unchanged since %s", state.Time.Format("2006-01-02 15:04:05"))
+}
+
+// warmUpRepository runs the custom warm-up steps of a repository, after the auto-detected
+// warm-up when the mode is extend, or the auto-detected warm-up alone when there are none
+func warmUpRepository(repoDir string, settings config.RepoSettings, opts *ProcessorOptions) ([]reporter.WarmUpResult, error) {
+	custom := settings.CustomWarmUp()
+
+	var results []warmup.StepResult
+	var errs []error
+	if len(custom) == 0 || settings.WarmUpMode == config.WarmUpExtend {
+		auto, err := warmup.PerformWithResults(repoDir)
+		results = append(results, auto...)
 		if err != nil {
-			opts.UI.Warning("Warm-up failed for %s: %v", targetPath, err)
-			// Don't return error for warm-up failures as they're not critical
+			errs = append(errs, err)
+		}
+	}
+
+	if len(custom) > 0 {
+		steps, err := warmUpSteps(custom)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			ran, err := warmup.RunSteps(repoDir, steps)
+			results = append(results, ran...)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	reported := make([]reporter.WarmUpResult, len(results))
+	for i, result := range results {
+		if result.Skipped != "" {
+			opts.UI.Warning("Skipped %s warm-up of %s: %s", result.Name, repoDir, result.Skipped)
 		} else {
-			opts.UI.Verbose("Warm-up completed for %s", targetPath)
+			opts.UI.Verbose("Warm-up step %s took %v", result.Name, result.Duration)
+		}
+		reported[i] = reporter.WarmUpResult{
+			Step:     result.Name,
+			Duration: result.Duration,
+			Success:  result.Err == nil,
+			Output:   result.Output,
+			Skipped:  result.Skipped,
+		}
+		if result.Err != nil {
+			reported[i].Error = result.Err.Error()
 		}
 	}
+	return reported, errors.Join(errs...)
+}
 
+// changedTrackedFiles returns the set of modified tracked files of a repository,
+// or nil when they cannot be listed
+func changedTrackedFiles(repoDir string, opts *ProcessorOptions) map[string]bool {
+	files, err := git.ChangedTrackedFiles(repoDir)
+	if err != nil {
+		opts.UI.Verbose("Not checking warm-up changes in %s: %v", repoDir, err)
+		return nil
+	}
+	set := make(map[string]bool, len(files))
+	for _, file := range files {
+		set[file] = true
+	}
+	return set
+}
+
+// revertWarmUpChanges restores the tracked files modified by a warm-up, skipping those
+// in dirty, and returns the restored files
+func revertWarmUpChanges(repoDir string, dirty map[string]bool, opts *ProcessorOptions) []string {
+	files, err := git.ChangedTrackedFiles(repoDir)
+	if err != nil {
+		opts.UI.Verbose("Not checking warm-up changes in %s: %v", repoDir, err)
+		return nil
+	}
+
+	var changed []string
+	for _, file := range files {
+		if !dirty[file] {
+			changed = append(changed, file)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	if err := git.RestoreFiles(repoDir, changed); err != nil {
+		opts.UI.Warning("Warm-up modified tracked files in %s: %s (revert failed: %v)", repoDir, strings.Join(changed, ", "), err)
+		return nil
+	}
+	opts.UI.Warning("Warm-up modified tracked files in %s: %s (reverted)", repoDir, strings.Join(changed, ", "))
+	return changed
+}
+
+// anySkipped reports whether a warm-up step was skipped
+func anySkipped(results []reporter.WarmUpResult) bool {
+	for _, result := range results {
+		if result.Skipped != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// warmUpSteps converts configured warm-up steps into runnable steps
+func warmUpSteps(configured []config.WarmUpStep) ([]warmup.Step, error) {
+	steps := make([]warmup.Step, len(configured))
+	for i, step := range configured {
+		steps[i] = warmup.Step{Command: step.Run, Dir: step.Dir}
+		if step.Timeout != "" {
+			timeout, err := time.ParseDuration(step.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timeout %q for warm-up step %s: %w", step.Timeout, strings.Join(step.Run, " "), err)
+			}
+			steps[i].Timeout = timeout
+		}
+
+		keys := make([]string, 0, len(step.Env))
+		for key := range step.Env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			steps[i].Env = append(steps[i].Env, key+"="+step.Env[key])
+		}
+	}
+	return steps, nil
+}
+
+// ensureWorktrees creates the configured worktrees of a repository that do not exist yet
+func ensureWorktrees(repoDir string, worktrees []config.Worktree, opts *ProcessorOptions) error {
+	for _, worktree := range worktrees {
+		worktreePath := filepath.Join(repoDir, worktree.Path)
+		if _, err := os.Stat(worktreePath); err == nil {
+			continue
+		}
+		opts.UI.Verbose("Creating worktree %s (%s)", worktreePath, worktree.Branch)
+		if err := git.AddWorktree(repoDir, worktree.Path, worktree.Branch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runHooks runs the commands of a repository hook inside the repository directory
+func runHooks(repoDir, name string, commands [][]string, opts *ProcessorOptions) error {
+	for _, command := range commands {
+		if len(command) == 0 {
+			return fmt.Errorf("%s hook: empty command", name)
+		}
+		opts.UI.Verbose("Running %s hook: %s", name, strings.Join(command, " "))
+		cmd := exec.Command(command[0], command[1:]...)
+		cmd.Dir = repoDir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%s hook failed: %w\nOutput: %s", name, err, string(output))
+		}
+	}
 	return nil
 }
 
 // shouldWarmUp determines if warm-up should be performed for a repository
 func shouldWarmUp(repo config.Repo, site config.SiteConfig) bool {
-	return repo.WarmUp || site.WarmUpAll
-} 
\ No newline at end of file
+	return repo.Settings(site).WarmUp
+}
\ No newline at end of file
@@ -1,20 +1,42 @@
 package process
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/khicago/repoll/internal/cli"
 	"github.com/khicago/repoll/internal/config"
+	"github.com/khicago/repoll/internal/git"
 	"github.com/khicago/repoll/internal/reporter"
 )
 
+// testOptions 返回用于测试的静默处理选项
+func testOptions() *ProcessorOptions {
+	return &ProcessorOptions{UI: cli.NewUIManager(true, false)}
+}
+
+// processOne 通过抓取和预热流水线处理单个仓库，失败时返回记录的错误
+func processOne(repo config.Repo, site config.SiteConfig, opts *ProcessorOptions) (*reporter.MakeAction, error) {
+	site.Repos = []config.Repo{repo}
+	report := &reporter.MakeReport{}
+	processSites([]config.SiteConfig{site}, report, opts, nil)
+	action := report.Actions[0]
+	if !action.Success {
+		return action, errors.New(action.Error)
+	}
+	return action, nil
+}
+
 func TestProcessConfig_InvalidFile(t *testing.T) {
 	report := &reporter.MakeReport{Actions: make([]*reporter.MakeAction, 0)}
 	
-	err := ProcessConfig("non-existent-file.toml", report)
+	err := ProcessConfig("non-existent-file.toml", report, nil)
 	if err == nil {
 		t.Error("Expected error for non-existent config file")
 	}
@@ -34,7 +56,7 @@ remote = "invalid toml`
 	}
 	
 	report := &reporter.MakeReport{Actions: make([]*reporter.MakeAction, 0)}
-	err = ProcessConfig(configFile, report)
+	err = ProcessConfig(configFile, report, nil)
 	
 	if err == nil {
 		t.Error("Expected error for invalid TOML")
@@ -52,7 +74,7 @@ func TestProcessConfig_EmptyConfig(t *testing.T) {
 	}
 	
 	report := &reporter.MakeReport{Actions: make([]*reporter.MakeAction, 0)}
-	err = ProcessConfig(configFile, report)
+	err = ProcessConfig(configFile, report, nil)
 	
 	// 空配置应该不报错，但也不会有任何操作
 	if err != nil {
@@ -116,10 +138,7 @@ func TestProcessSite_EmptyRepos(t *testing.T) {
 	
 	report := &reporter.MakeReport{Actions: make([]*reporter.MakeAction, 0)}
 	
-	err := processSite(site, report)
-	if err != nil {
-		t.Errorf("processSite failed: %v", err)
-	}
+	processSites([]config.SiteConfig{site}, report, testOptions(), nil)
 	
 	if len(report.Actions) != 0 {
 		t.Errorf("Expected no actions for empty repos, got %d", len(report.Actions))
@@ -142,10 +161,7 @@ func TestProcessSite_InvalidDirectory(t *testing.T) {
 	report := &reporter.MakeReport{Actions: make([]*reporter.MakeAction, 0)}
 	
 	// 这应该会失败，因为目录无效
-	err := processSite(site, report)
-	if err != nil {
-		t.Logf("processSite failed as expected: %v", err)
-	}
+	processSites([]config.SiteConfig{site}, report, testOptions(), nil)
 	
 	// 即使失败，也应该记录操作
 	if len(report.Actions) != 1 {
@@ -174,7 +190,7 @@ func TestProcessSite_InvalidDirectory(t *testing.T) {
 	}
 }
 
-func TestProcessRepository_BasicFunctionality(t *testing.T) {
+func TestProcessSites_BasicFunctionality(t *testing.T) {
 	// 创建临时目录
 	tempDir := t.TempDir()
 	
@@ -191,38 +207,38 @@ func TestProcessRepository_BasicFunctionality(t *testing.T) {
 		WarmUpAll:    false,
 	}
 	
-	// 测试processRepository函数不会panic
+	// 测试processSites函数不会panic
 	defer func() {
 		if r := recover(); r != nil {
-			t.Errorf("processRepository panicked: %v", r)
+			t.Errorf("processSites panicked: %v", r)
 		}
 	}()
 	
-	// 调用processRepository（预期会失败，但不应该panic）
-	err := processRepository(repo, site)
+	// 调用processSites（预期会失败，但不应该panic）
+	_, err := processOne(repo, site, testOptions())
 	if err != nil {
-		t.Logf("processRepository failed as expected: %v", err)
+		t.Logf("processSites failed as expected: %v", err)
 	}
 }
 
-func TestProcessRepository_EmptyConfig(t *testing.T) {
+func TestProcessSites_EmptyConfig(t *testing.T) {
 	// 测试空配置
 	repo := config.Repo{}
 	site := config.SiteConfig{}
 	
 	defer func() {
 		if r := recover(); r != nil {
-			t.Errorf("processRepository panicked with empty config: %v", r)
+			t.Errorf("processSites panicked with empty config: %v", r)
 		}
 	}()
 	
-	err := processRepository(repo, site)
+	_, err := processOne(repo, site, testOptions())
 	if err != nil {
-		t.Logf("processRepository failed with empty config as expected: %v", err)
+		t.Logf("processSites failed with empty config as expected: %v", err)
 	}
 }
 
-func TestProcessRepository_InvalidPath(t *testing.T) {
+func TestProcessSites_InvalidPath(t *testing.T) {
 	// 测试无效路径
 	repo := config.Repo{
 		Repo:   "test/repo",
@@ -238,17 +254,17 @@ func TestProcessRepository_InvalidPath(t *testing.T) {
 	
 	defer func() {
 		if r := recover(); r != nil {
-			t.Errorf("processRepository panicked with invalid path: %v", r)
+			t.Errorf("processSites panicked with invalid path: %v", r)
 		}
 	}()
 	
-	err := processRepository(repo, site)
+	_, err := processOne(repo, site, testOptions())
 	if err != nil {
-		t.Logf("processRepository failed with invalid path as expected: %v", err)
+		t.Logf("processSites failed with invalid path as expected: %v", err)
 	}
 }
 
-func TestProcessRepository_ValidDirectory(t *testing.T) {
+func TestProcessSites_ValidDirectory(t *testing.T) {
 	// 创建临时目录
 	tempDir := t.TempDir()
 	
@@ -266,19 +282,19 @@ func TestProcessRepository_ValidDirectory(t *testing.T) {
 	
 	defer func() {
 		if r := recover(); r != nil {
-			t.Errorf("processRepository panicked with valid directory: %v", r)
+			t.Errorf("processSites panicked with valid directory: %v", r)
 		}
 	}()
 	
-	err := processRepository(repo, site)
+	_, err := processOne(repo, site, testOptions())
 	if err != nil {
-		t.Logf("processRepository failed as expected (no real Git repo): %v", err)
+		t.Logf("processSites failed as expected (no real Git repo): %v", err)
 	}
 }
 
-// 新增的测试用例来提高 processRepository 覆盖率
+// 新增的测试用例来提高 processSites 覆盖率
 
-func TestProcessRepository_ExistingRepository(t *testing.T) {
+func TestProcessSites_ExistingRepository(t *testing.T) {
 	// 创建临时目录
 	tempDir := t.TempDir()
 	
@@ -302,9 +318,9 @@ func TestProcessRepository_ExistingRepository(t *testing.T) {
 	}
 	
 	// 测试处理已存在的仓库（会尝试更新）
-	err = processRepository(repo, site)
+	_, err = processOne(repo, site, testOptions())
 	if err != nil {
-		t.Logf("processRepository failed as expected (not a real Git repo): %v", err)
+		t.Logf("processSites failed as expected (not a real Git repo): %v", err)
 		// 验证错误信息包含更新相关的内容
 		if !strings.Contains(err.Error(), "update") {
 			t.Errorf("Expected error to mention 'update', got: %v", err)
@@ -312,7 +328,7 @@ func TestProcessRepository_ExistingRepository(t *testing.T) {
 	}
 }
 
-func TestProcessRepository_WithWarmUp(t *testing.T) {
+func TestProcessSites_WithWarmUp(t *testing.T) {
 	// 创建临时目录
 	tempDir := t.TempDir()
 	
@@ -328,9 +344,9 @@ func TestProcessRepository_WithWarmUp(t *testing.T) {
 		WarmUpAll:    false,
 	}
 	
-	err := processRepository(repo, site)
+	_, err := processOne(repo, site, testOptions())
 	if err != nil {
-		t.Logf("processRepository failed as expected: %v", err)
+		t.Logf("processSites failed as expected: %v", err)
 		// 验证错误是来自克隆操作，而不是预热操作
 		if !strings.Contains(err.Error(), "clone") {
 			t.Errorf("Expected error to mention 'clone', got: %v", err)
@@ -338,7 +354,7 @@ func TestProcessRepository_WithWarmUp(t *testing.T) {
 	}
 }
 
-func TestProcessRepository_SiteWarmUpAll(t *testing.T) {
+func TestProcessSites_SiteWarmUpAll(t *testing.T) {
 	// 创建临时目录
 	tempDir := t.TempDir()
 	
@@ -354,13 +370,13 @@ func TestProcessRepository_SiteWarmUpAll(t *testing.T) {
 		WarmUpAll:    true, // 站点级别启用预热
 	}
 	
-	err := processRepository(repo, site)
+	_, err := processOne(repo, site, testOptions())
 	if err != nil {
-		t.Logf("processRepository failed as expected: %v", err)
+		t.Logf("processSites failed as expected: %v", err)
 	}
 }
 
-func TestProcessRepository_WithRename(t *testing.T) {
+func TestProcessSites_WithRename(t *testing.T) {
 	// 创建临时目录
 	tempDir := t.TempDir()
 	
@@ -377,9 +393,9 @@ func TestProcessRepository_WithRename(t *testing.T) {
 		WarmUpAll:    false,
 	}
 	
-	err := processRepository(repo, site)
+	_, err := processOne(repo, site, testOptions())
 	if err != nil {
-		t.Logf("processRepository failed as expected: %v", err)
+		t.Logf("processSites failed as expected: %v", err)
 	}
 	
 	// 验证目标路径使用了重命名后的名称
@@ -388,7 +404,7 @@ func TestProcessRepository_WithRename(t *testing.T) {
 	}
 }
 
-func TestProcessRepository_EmptyRepoName(t *testing.T) {
+func TestProcessSites_EmptyRepoName(t *testing.T) {
 	// 测试空仓库名
 	tempDir := t.TempDir()
 	
@@ -406,17 +422,17 @@ func TestProcessRepository_EmptyRepoName(t *testing.T) {
 	
 	defer func() {
 		if r := recover(); r != nil {
-			t.Errorf("processRepository panicked with empty repo name: %v", r)
+			t.Errorf("processSites panicked with empty repo name: %v", r)
 		}
 	}()
 	
-	err := processRepository(repo, site)
+	_, err := processOne(repo, site, testOptions())
 	if err != nil {
-		t.Logf("processRepository failed with empty repo name as expected: %v", err)
+		t.Logf("processSites failed with empty repo name as expected: %v", err)
 	}
 }
 
-func TestProcessRepository_EmptyRemotePrefix(t *testing.T) {
+func TestProcessSites_EmptyRemotePrefix(t *testing.T) {
 	// 测试空远程前缀
 	tempDir := t.TempDir()
 	
@@ -434,17 +450,17 @@ func TestProcessRepository_EmptyRemotePrefix(t *testing.T) {
 	
 	defer func() {
 		if r := recover(); r != nil {
-			t.Errorf("processRepository panicked with empty remote prefix: %v", r)
+			t.Errorf("processSites panicked with empty remote prefix: %v", r)
 		}
 	}()
 	
-	err := processRepository(repo, site)
+	_, err := processOne(repo, site, testOptions())
 	if err != nil {
-		t.Logf("processRepository failed with empty remote prefix as expected: %v", err)
+		t.Logf("processSites failed with empty remote prefix as expected: %v", err)
 	}
 }
 
-func TestProcessRepository_ComplexRepoPath(t *testing.T) {
+func TestProcessSites_ComplexRepoPath(t *testing.T) {
 	// 测试复杂的仓库路径
 	tempDir := t.TempDir()
 	
@@ -460,9 +476,9 @@ func TestProcessRepository_ComplexRepoPath(t *testing.T) {
 		WarmUpAll:    false,
 	}
 	
-	err := processRepository(repo, site)
+	_, err := processOne(repo, site, testOptions())
 	if err != nil {
-		t.Logf("processRepository failed with complex path as expected: %v", err)
+		t.Logf("processSites failed with complex path as expected: %v", err)
 	}
 	
 	// 验证目标路径只使用了最后一部分作为目录名
@@ -471,7 +487,7 @@ func TestProcessRepository_ComplexRepoPath(t *testing.T) {
 	}
 }
 
-func TestProcessRepository_BothWarmUpEnabled(t *testing.T) {
+func TestProcessSites_BothWarmUpEnabled(t *testing.T) {
 	// 测试仓库和站点都启用预热的情况
 	tempDir := t.TempDir()
 	
@@ -487,9 +503,9 @@ func TestProcessRepository_BothWarmUpEnabled(t *testing.T) {
 		WarmUpAll:    true,
 	}
 	
-	err := processRepository(repo, site)
+	_, err := processOne(repo, site, testOptions())
 	if err != nil {
-		t.Logf("processRepository failed as expected: %v", err)
+		t.Logf("processSites failed as expected: %v", err)
 	}
 	
 	// 验证 shouldWarmUp 函数被正确调用
@@ -521,7 +537,7 @@ memo = "Test repository"
 	report := &reporter.MakeReport{Actions: make([]*reporter.MakeAction, 0)}
 	
 	// 这个测试主要验证配置解析，Git操作会失败但不影响测试
-	err = ProcessConfig(configFile, report)
+	err = ProcessConfig(configFile, report, nil)
 	
 	// 检查配置文件是否被正确解析（即使Git操作失败）
 	if err != nil {
@@ -546,7 +562,7 @@ memo = "Test repository"
 }
 
 func TestProcessConfig_NonExistentFile(t *testing.T) {
-	err := ProcessConfig("non-existent-file.toml", nil)
+	err := ProcessConfig("non-existent-file.toml", nil, nil)
 	if err == nil {
 		t.Error("Expected error for non-existent config file")
 	}
@@ -581,7 +597,7 @@ memo = "Test repository"
 		}
 	}()
 	
-	err = ProcessConfig(configFile, nil)
+	err = ProcessConfig(configFile, nil, nil)
 	// 预期会有错误，因为没有真实的Git仓库
 	if err != nil {
 		t.Logf("ProcessConfig completed with expected errors: %v", err)
@@ -619,7 +635,7 @@ memo = "Test repository"
 		}
 	}()
 	
-	err = ProcessConfig(configFile, &report)
+	err = ProcessConfig(configFile, &report, nil)
 	// 预期会有错误，因为没有真实的Git仓库
 	if err != nil {
 		t.Logf("ProcessConfig completed with expected errors: %v", err)
@@ -629,4 +645,461 @@ memo = "Test repository"
 	if len(report.Actions) == 0 {
 		t.Error("Expected at least one action in the report")
 	}
-} 
\ No newline at end of file
+} 
+// newLocalUpstream 创建名为 name.git 的本地上游仓库，返回可用作 remote 前缀的 file:// URL
+func newLocalUpstream(t *testing.T, name string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("Git not available, skipping test")
+	}
+
+	root := t.TempDir()
+	dir := filepath.Join(root, name+".git")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create upstream directory: %v", err)
+	}
+	for _, args := range [][]string{
+		{"init"},
+		{"commit", "--allow-empty", "-m", "initial"},
+		{"branch", "develop"},
+	} {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=repoll", "-c", "user.email=repoll@example.com"}, args...)...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	return "file://" + root + "/"
+}
+
+func TestProcessSites_SiteDefaults(t *testing.T) {
+	remote := newLocalUpstream(t, "service")
+	workDir := t.TempDir()
+
+	site := config.SiteConfig{
+		RemotePrefix: remote,
+		Dir:          workDir,
+		WarmUpAll:    true,
+		Defaults: &config.SiteDefaults{
+			Branch:         "develop",
+			WarmUpCommands: [][]string{{"touch", "warmed.txt"}},
+			Hooks: &config.Hooks{
+				PostClone:  [][]string{{"touch", "cloned.txt"}},
+				PostUpdate: [][]string{{"touch", "updated.txt"}},
+			},
+		},
+	}
+	repo := config.Repo{Repo: "service"}
+	targetPath := repo.FullPath(site)
+
+	// 首次运行：克隆、执行 post_clone 钩子和自定义预热命令
+	if _, err := processOne(repo, site, testOptions()); err != nil {
+		t.Fatalf("processSites failed on clone: %v", err)
+	}
+	for _, name := range []string{"cloned.txt", "warmed.txt"} {
+		if _, err := os.Stat(filepath.Join(targetPath, name)); err != nil {
+			t.Errorf("Expected %s to be created: %v", name, err)
+		}
+	}
+
+	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	cmd.Dir = targetPath
+	branch, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("Failed to read branch: %v", err)
+	}
+	if strings.TrimSpace(string(branch)) != "develop" {
+		t.Errorf("Expected default branch 'develop' to be checked out, got %s", branch)
+	}
+
+	// 再次运行：更新并执行 post_update 钩子
+	if _, err := processOne(repo, site, testOptions()); err != nil {
+		t.Fatalf("processSites failed on update: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(targetPath, "updated.txt")); err != nil {
+		t.Errorf("Expected post_update hook to run: %v", err)
+	}
+}
+
+func TestProcessSites_FailingHook(t *testing.T) {
+	remote := newLocalUpstream(t, "service")
+
+	site := config.SiteConfig{
+		RemotePrefix: remote,
+		Dir:          t.TempDir(),
+	}
+	repo := config.Repo{
+		Repo:  "service",
+		Hooks: &config.Hooks{PostClone: [][]string{{"false"}}},
+	}
+
+	_, err := processOne(repo, site, testOptions())
+	if err == nil || !strings.Contains(err.Error(), "post_clone hook failed") {
+		t.Errorf("Expected post_clone hook failure, got: %v", err)
+	}
+}
+
+func TestProcessSites_SubmodulesAndWorktrees(t *testing.T) {
+	remote := newLocalUpstream(t, "service")
+	upstream := strings.TrimPrefix(remote, "file://") + "service.git"
+
+	// 本地 file:// 子模块需要显式允许
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "protocol.file.allow")
+	t.Setenv("GIT_CONFIG_VALUE_0", "always")
+
+	libRemote := newLocalUpstream(t, "lib")
+	for _, args := range [][]string{
+		{"submodule", "add", libRemote + "lib.git", "lib"},
+		{"commit", "-m", "add lib"},
+	} {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=repoll", "-c", "user.email=repoll@example.com"}, args...)...)
+		cmd.Dir = upstream
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+
+	site := config.SiteConfig{
+		RemotePrefix: remote,
+		Dir:          t.TempDir(),
+	}
+	repo := config.Repo{
+		Repo:       "service",
+		Submodules: true,
+		Worktrees:  []config.Worktree{{Path: "../service-develop", Branch: "develop"}},
+	}
+	targetPath := repo.FullPath(site)
+
+	if _, err := processOne(repo, site, testOptions()); err != nil {
+		t.Fatalf("processSites failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(targetPath, "lib", ".git")); err != nil {
+		t.Errorf("Expected submodule to be checked out: %v", err)
+	}
+
+	worktreePath := filepath.Join(site.Dir, "service-develop")
+	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	cmd.Dir = worktreePath
+	branch, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("Expected worktree at %s: %v", worktreePath, err)
+	}
+	if strings.TrimSpace(string(branch)) != "develop" {
+		t.Errorf("Expected worktree on develop, got %s", branch)
+	}
+
+	// 再次运行时已有的工作树保持不变
+	if _, err := processOne(repo, site, testOptions()); err != nil {
+		t.Errorf("processSites failed on update: %v", err)
+	}
+}
+
+func TestProcessSites_PinnedCommit(t *testing.T) {
+	remote := newLocalUpstream(t, "service")
+	upstream := strings.TrimPrefix(remote, "file://") + "service.git"
+
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = upstream
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("Failed to read upstream commit: %v", err)
+	}
+	pinned := strings.TrimSpace(string(output))
+
+	cmd = exec.Command("git", "-c", "user.name=repoll", "-c", "user.email=repoll@example.com", "commit", "--allow-empty", "-m", "later")
+	cmd.Dir = upstream
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to commit: %v\n%s", err, output)
+	}
+
+	site := config.SiteConfig{
+		RemotePrefix: remote,
+		Dir:          t.TempDir(),
+	}
+	repo := config.Repo{Repo: "service", Commit: pinned}
+	targetPath := repo.FullPath(site)
+
+	// 克隆和更新都停留在固定的提交上
+	for _, step := range []string{"clone", "update"} {
+		if _, err := processOne(repo, site, testOptions()); err != nil {
+			t.Fatalf("processSites failed on %s: %v", step, err)
+		}
+		head, err := git.GetHeadCommit(targetPath)
+		if err != nil || head != pinned {
+			t.Errorf("Expected HEAD at %s after %s, got %s (%v)", pinned, step, head, err)
+		}
+	}
+}
+
+func TestProcessSites_TagBranch(t *testing.T) {
+	remote := newLocalUpstream(t, "service")
+	upstream := strings.TrimPrefix(remote, "file://") + "service.git"
+
+	gitUpstream := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=repoll", "-c", "user.email=repoll@example.com"}, args...)...)
+		cmd.Dir = upstream
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+	gitUpstream("tag", "v1")
+	tagged := gitUpstream("rev-parse", "HEAD")
+	gitUpstream("commit", "--allow-empty", "-m", "two")
+
+	site := config.SiteConfig{
+		RemotePrefix: remote,
+		Dir:          t.TempDir(),
+	}
+	tag := "v1"
+	repo := config.Repo{Repo: "service", Branch: &tag}
+	targetPath := repo.FullPath(site)
+
+	// 检出标签的克隆在更新时不会被拉取到上游最新提交
+	for _, step := range []string{"clone", "update"} {
+		if _, err := processOne(repo, site, testOptions()); err != nil {
+			t.Fatalf("processSites failed on %s: %v", step, err)
+		}
+		head, err := git.GetHeadCommit(targetPath)
+		if err != nil || head != tagged {
+			t.Errorf("Expected HEAD at tag v1 (%s) after %s, got %s (%v)", tagged, step, head, err)
+		}
+	}
+}
+
+func TestProcessSite_WarmUpSteps(t *testing.T) {
+	remote := newLocalUpstream(t, "service")
+	workDir := t.TempDir()
+
+	site := config.SiteConfig{
+		RemotePrefix: remote,
+		Dir:          workDir,
+		Repos: []config.Repo{{
+			Repo:           "service",
+			WarmUp:         true,
+			WarmUpCommands: [][]string{{"mkdir", "build"}},
+			WarmUpSteps: []config.WarmUpStep{
+				{Run: []string{"sh", "-c", "echo $MODE > mode.txt"}, Dir: "build", Env: map[string]string{"MODE": "dev"}, Timeout: "1m"},
+				{Run: []string{"sh", "-c", "echo setup failed; exit 1"}},
+			},
+		}},
+	}
+
+	report := &reporter.MakeReport{}
+	processSites([]config.SiteConfig{site}, report, testOptions(), nil)
+
+	// 预热失败不影响仓库处理结果，但每个步骤的输出都记录在报告中
+	action := report.Actions[0]
+	if !action.Success {
+		t.Errorf("Warm-up failure should not fail the repository: %s", action.Error)
+	}
+	if len(action.WarmUp) != 3 {
+		t.Fatalf("Expected 3 warm-up results, got %+v", action.WarmUp)
+	}
+	if !action.WarmUp[0].Success || !action.WarmUp[1].Success || action.WarmUp[2].Success {
+		t.Errorf("Unexpected warm-up results: %+v", action.WarmUp)
+	}
+	if !strings.Contains(action.WarmUp[2].Output, "setup failed") {
+		t.Errorf("Expected failing step output in report, got %q", action.WarmUp[2].Output)
+	}
+
+	content, err := os.ReadFile(filepath.Join(workDir, "service", "build", "mode.txt"))
+	if err != nil || strings.TrimSpace(string(content)) != "dev" {
+		t.Errorf("Expected step to run in its directory with its environment, got %q (%v)", content, err)
+	}
+}
+
+func TestWarmUpSteps_InvalidTimeout(t *testing.T) {
+	_, err := warmUpSteps([]config.WarmUpStep{{Run: []string{"make"}, Timeout: "soon"}})
+	if err == nil || !strings.Contains(err.Error(), `invalid timeout "soon"`) {
+		t.Errorf("Expected invalid timeout error, got %v", err)
+	}
+}
+
+func TestProcessSites_WarmUpFingerprint(t *testing.T) {
+	remote := newLocalUpstream(t, "service")
+	workDir := t.TempDir()
+
+	site := config.SiteConfig{
+		RemotePrefix: remote,
+		Dir:          workDir,
+		WarmUpAll:    true,
+		Defaults: &config.SiteDefaults{
+			WarmUpCommands: [][]string{{"sh", "-c", "echo run >> warmed.txt"}},
+		},
+	}
+	repo := config.Repo{Repo: "service"}
+	warmedFile := filepath.Join(repo.FullPath(site), "warmed.txt")
+
+	runs := func() int {
+		t.Helper()
+		content, err := os.ReadFile(warmedFile)
+		if err != nil {
+			t.Fatalf("Failed to read warm-up output: %v", err)
+		}
+		return strings.Count(string(content), "run")
+	}
+
+	if _, err := processOne(repo, site, testOptions()); err != nil {
+		t.Fatalf("processSites failed: %v", err)
+	}
+
+	// 依赖清单和预热命令未变化时跳过预热，并记录原因
+	action, err := processOne(repo, site, testOptions())
+	if err != nil {
+		t.Fatalf("processSites failed: %v", err)
+	}
+	if runs() != 1 || !strings.Contains(action.WarmUpSkipped, "unchanged") {
+		t.Errorf("Expected the second warm-up to be skipped, got %d runs and reason %q", runs(), action.WarmUpSkipped)
+	}
+
+	// --force-warm-up 强制重新预热
+	opts := testOptions()
+	opts.ForceWarmUp = true
+	if _, err := processOne(repo, site, opts); err != nil {
+		t.Fatalf("processSites failed: %v", err)
+	}
+	if runs() != 2 {
+		t.Errorf("Expected forced warm-up to run, got %d runs", runs())
+	}
+
+	// 修改预热命令后重新预热
+	site.Defaults.WarmUpCommands = [][]string{{"sh", "-c", "echo run >> warmed.txt; echo changed"}}
+	if _, err := processOne(repo, site, testOptions()); err != nil {
+		t.Fatalf("processSites failed: %v", err)
+	}
+	if runs() != 3 {
+		t.Errorf("Expected changed warm-up commands to run, got %d runs", runs())
+	}
+}
+
+func TestProcessSites_WarmUpFingerprintAfterInstall(t *testing.T) {
+	remote := newLocalUpstream(t, "service")
+	workDir := t.TempDir()
+
+	site := config.SiteConfig{RemotePrefix: remote, Dir: workDir}
+	repo := config.Repo{Repo: "service"}
+	if _, err := processOne(repo, site, testOptions()); err != nil {
+		t.Fatalf("processSites failed: %v", err)
+	}
+	repoDir := repo.FullPath(site)
+	if err := os.WriteFile(filepath.Join(repoDir, "package.json"), []byte(`{"name": "service"}`), 0644); err != nil {
+		t.Fatalf("Failed to write package.json: %v", err)
+	}
+
+	// 预热创建 node_modules，记录的指纹包含它，第二次运行即跳过
+	site.WarmUpAll = true
+	site.Defaults = &config.SiteDefaults{WarmUpCommands: [][]string{{"mkdir", "-p", "node_modules"}}}
+	if _, err := processOne(repo, site, testOptions()); err != nil {
+		t.Fatalf("processSites failed: %v", err)
+	}
+	action, err := processOne(repo, site, testOptions())
+	if err != nil {
+		t.Fatalf("processSites failed: %v", err)
+	}
+	if !strings.Contains(action.WarmUpSkipped, "unchanged") || len(action.WarmUp) != 0 {
+		t.Errorf("Expected the second warm-up to be skipped, got reason %q and steps %+v", action.WarmUpSkipped, action.WarmUp)
+	}
+}
+
+func TestProcessSites_WarmUpRevertsTrackedFiles(t *testing.T) {
+	remote := newLocalUpstream(t, "service")
+	workDir := t.TempDir()
+
+	site := config.SiteConfig{RemotePrefix: remote, Dir: workDir}
+	repo := config.Repo{Repo: "service"}
+	if _, err := processOne(repo, site, testOptions()); err != nil {
+		t.Fatalf("processSites failed: %v", err)
+	}
+
+	// 在克隆中提交两个跟踪文件，并在预热前修改其中一个
+	repoDir := repo.FullPath(site)
+	for name, content := range map[string]string{"deps.lock": "locked\n", "notes.txt": "notes\n"} {
+		if err := os.WriteFile(filepath.Join(repoDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	for _, args := range [][]string{{"add", "."}, {"commit", "-m", "files"}} {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=repoll", "-c", "user.email=repoll@example.com"}, args...)...)
+		cmd.Dir = repoDir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "notes.txt"), []byte("local edit\n"), 0644); err != nil {
+		t.Fatalf("Failed to edit notes.txt: %v", err)
+	}
+
+	site.WarmUpAll = true
+	site.Defaults = &config.SiteDefaults{WarmUpCommands: [][]string{{"sh", "-c", "echo relocked > deps.lock; echo more >> notes.txt"}}}
+
+	// 预热修改的跟踪文件被还原，预热前已有的本地修改保持不变
+	action, err := processOne(repo, site, testOptions())
+	if err != nil {
+		t.Fatalf("processSites failed: %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(repoDir, "deps.lock")); string(content) != "locked\n" {
+		t.Errorf("Expected deps.lock to be reverted, got %q", content)
+	}
+	if content, _ := os.ReadFile(filepath.Join(repoDir, "notes.txt")); !strings.HasPrefix(string(content), "local edit\n") {
+		t.Errorf("Expected local edits in notes.txt to be kept, got %q", content)
+	}
+	if !reflect.DeepEqual(action.WarmUpReverted, []string{"deps.lock"}) {
+		t.Errorf("Expected deps.lock to be reported as reverted, got %v", action.WarmUpReverted)
+	}
+
+	// warm_up_mutable 保留预热对跟踪文件的修改
+	site.Defaults.WarmUpMutable = true
+	opts := testOptions()
+	opts.ForceWarmUp = true
+	action, err = processOne(repo, site, opts)
+	if err != nil {
+		t.Fatalf("processSites failed: %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(repoDir, "deps.lock")); string(content) != "relocked\n" {
+		t.Errorf("Expected deps.lock to keep the warm-up change, got %q", content)
+	}
+	if len(action.WarmUpReverted) != 0 {
+		t.Errorf("Expected nothing to be reverted, got %v", action.WarmUpReverted)
+	}
+}
+
+func TestProcessSites_MissingToolchain(t *testing.T) {
+	remote := newLocalUpstream(t, "service")
+	workDir := t.TempDir()
+
+	site := config.SiteConfig{RemotePrefix: remote, Dir: workDir}
+	repo := config.Repo{Repo: "service"}
+	if _, err := processOne(repo, site, testOptions()); err != nil {
+		t.Fatalf("processSites failed: %v", err)
+	}
+	repoDir := repo.FullPath(site)
+	if err := os.WriteFile(filepath.Join(repoDir, "Cargo.toml"), []byte("[package]\nname = \"service\"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write Cargo.toml: %v", err)
+	}
+
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("Git not available, skipping test")
+	}
+	t.Setenv("PATH", filepath.Dir(gitPath))
+	if _, err := exec.LookPath("cargo"); err == nil {
+		t.Skip("cargo is installed next to git, skipping test")
+	}
+
+	// 缺少 cargo 时跳过 Rust 预热，不算失败，也不记录预热状态
+	site.WarmUpAll = true
+	action, err := processOne(repo, site, testOptions())
+	if err != nil {
+		t.Fatalf("processSites failed: %v", err)
+	}
+	if len(action.WarmUp) != 1 || !action.WarmUp[0].Success || !strings.Contains(action.WarmUp[0].Skipped, "missing toolchain cargo") {
+		t.Fatalf("Expected the rust warm-up to be skipped, got %+v", action.WarmUp)
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, ".git", "repoll", warmUpStateFile)); !os.IsNotExist(err) {
+		t.Errorf("Expected no warm-up state after a skipped step, got %v", err)
+	}
+}
@@ -0,0 +1,212 @@
+package reporter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Row highlights in Markdown and HTML reports
+const (
+	highlightFailed = "failed"
+	highlightDirty  = "dirty"
+)
+
+// document is the table model shared by the Markdown and HTML reports
+type document struct {
+	Title   string
+	Empty   string
+	Summary []documentStat
+	Columns []documentColumn
+	Sites   []documentSite
+}
+
+type documentStat struct {
+	Label string
+	Value string
+}
+
+type documentColumn struct {
+	Name string
+	// Sortable columns carry a numeric sort key in their cells
+	Sortable bool
+}
+
+type documentSite struct {
+	Name string
+	Rows []documentRow
+}
+
+type documentRow struct {
+	// Highlight is highlightFailed, highlightDirty or empty
+	Highlight string
+	Cells     []documentCell
+}
+
+type documentCell struct {
+	// Text may span several lines
+	Text string
+	Sort int64
+}
+
+// addRow appends a row to the table of its site, creating the table on first use
+func (doc *document) addRow(site, noSite string, row documentRow) {
+	if site == "" {
+		site = noSite
+	}
+	for i := range doc.Sites {
+		if doc.Sites[i].Name == site {
+			doc.Sites[i].Rows = append(doc.Sites[i].Rows, row)
+			return
+		}
+	}
+	doc.Sites = append(doc.Sites, documentSite{Name: site, Rows: []documentRow{row}})
+}
+
+// document builds the processing report tables, one per site in configuration order
+func (mr *MakeReport) document() document {
+	doc := document{
+		Title: "Repository Processing Report",
+		Empty: "No actions performed.",
+		Columns: []documentColumn{
+			{Name: "Repository"}, {Name: "Action"}, {Name: "Status"},
+			{Name: "Duration", Sortable: true}, {Name: "Warm-up"}, {Name: "Notes"},
+		},
+	}
+
+	successCount := 0
+	var totalDuration time.Duration
+	for _, action := range mr.Actions {
+		totalDuration += action.Duration
+		row := documentRow{}
+		status := "✅ success"
+		if action.Success {
+			successCount++
+		} else {
+			status = "❌ failed"
+			row.Highlight = highlightFailed
+		}
+
+		var steps []string
+		for _, step := range action.WarmUp {
+			steps = append(steps, warmUpStepSummary(step))
+		}
+
+		var notes []string
+		if action.Memo != "" {
+			notes = append(notes, action.Memo)
+		}
+		if !action.Success && action.Error != "" {
+			notes = append(notes, action.Error)
+		}
+		if action.WarmUpSkipped != "" {
+			notes = append(notes, "Warm-up skipped: "+action.WarmUpSkipped)
+		}
+		if len(action.WarmUpReverted) > 0 {
+			notes = append(notes, "Warm-up changes reverted: "+strings.Join(action.WarmUpReverted, ", "))
+		}
+
+		row.Cells = []documentCell{
+			{Text: action.Repository},
+			{Text: action.Kind},
+			{Text: status},
+			{Text: formatDuration(action.Duration), Sort: action.Duration.Milliseconds()},
+			{Text: strings.Join(steps, "\n")},
+			{Text: strings.Join(notes, "\n")},
+		}
+		doc.addRow(action.Site, "Unknown site", row)
+	}
+
+	// Configuration files that could not be processed are listed in a table of their own
+	for _, failure := range mr.ConfigFailures {
+		doc.addRow("", "Failed configurations", documentRow{
+			Highlight: highlightFailed,
+			Cells: []documentCell{
+				{Text: failure.Config}, {}, {Text: "❌ failed"}, {Text: formatDuration(0)}, {}, {Text: failure.Error},
+			},
+		})
+	}
+
+	doc.Summary = []documentStat{
+		{Label: "Total repositories", Value: fmt.Sprint(len(mr.Actions))},
+		{Label: "Successful", Value: fmt.Sprint(successCount)},
+		{Label: "Failed", Value: fmt.Sprint(len(mr.Actions) - successCount)},
+		{Label: "Total time", Value: formatDuration(totalDuration)},
+	}
+	if len(mr.ConfigFailures) > 0 {
+		doc.Summary = append(doc.Summary, documentStat{Label: "Failed configurations", Value: fmt.Sprint(len(mr.ConfigFailures))})
+	}
+	return doc
+}
+
+// warmUpStepSummary describes a warm-up step in one line followed by the tail of its output
+func warmUpStepSummary(step WarmUpResult) string {
+	var summary string
+	switch {
+	case step.Skipped != "":
+		return fmt.Sprintf("⏭️ %s skipped: %s", step.Step, step.Skipped)
+	case !step.Success:
+		summary = fmt.Sprintf("⚠️ %s (%s)", step.Step, formatDuration(step.Duration))
+		if step.Error != "" {
+			summary += ": " + step.Error
+		}
+	default:
+		summary = fmt.Sprintf("🔥 %s (%s)", step.Step, formatDuration(step.Duration))
+	}
+	return strings.Join(append([]string{summary}, outputTail(step.Output)...), "\n")
+}
+
+// document builds the discovery report tables, one per origin site in discovery order
+func (mr *MkconfReport) document() document {
+	doc := document{
+		Title:   "Repository Discovery Report",
+		Empty:   "No repositories discovered.",
+		Columns: []documentColumn{{Name: "Path"}, {Name: "Kind"}, {Name: "Origin"}, {Name: "Changes"}},
+	}
+
+	originCount := 0
+	uncommittedCount := 0
+	unmergedCount := 0
+	for _, action := range mr.Actions {
+		row := documentRow{}
+		if action.HasOrigin {
+			originCount++
+		}
+
+		var changes []string
+		if action.Uncommitted {
+			changes = append(changes, "⚠️ uncommitted")
+			uncommittedCount++
+		}
+		if action.Unmerged {
+			changes = append(changes, "⚠️ unmerged")
+			unmergedCount++
+		}
+		if len(changes) > 0 {
+			row.Highlight = highlightDirty
+		} else {
+			changes = append(changes, "clean")
+		}
+
+		row.Cells = []documentCell{
+			{Text: action.Path},
+			{Text: action.Kind},
+			{Text: action.Origin},
+			{Text: strings.Join(changes, "\n")},
+		}
+		doc.addRow(action.Site, "No origin", row)
+	}
+
+	doc.Summary = []documentStat{
+		{Label: "Total repositories", Value: fmt.Sprint(len(mr.Actions))},
+		{Label: "With origin", Value: fmt.Sprint(originCount)},
+		{Label: "With uncommitted changes", Value: fmt.Sprint(uncommittedCount)},
+		{Label: "With unmerged changes", Value: fmt.Sprint(unmergedCount)},
+	}
+	return doc
+}
+
+// formatDuration rounds a duration to milliseconds for display
+func formatDuration(d time.Duration) string {
+	return d.Round(time.Millisecond).String()
+}
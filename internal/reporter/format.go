@@ -0,0 +1,362 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// SchemaVersion is the version of the JSON report schema. It changes only when fields
+// are removed or change meaning; new fields may be added within a version.
+const SchemaVersion = 1
+
+// Report output formats
+const (
+	FormatText     = "text"
+	FormatJSON     = "json"
+	FormatNDJSON   = "ndjson"
+	FormatJUnit    = "junit"
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+// Formats lists the supported report formats
+var Formats = []string{FormatText, FormatJSON, FormatNDJSON, FormatJUnit, FormatMarkdown, FormatHTML}
+
+// Renderer is a report that can be written in every supported format
+type Renderer interface {
+	// Report renders the human-readable text report
+	Report() string
+	// JSON renders the report as a single JSON document
+	JSON() ([]byte, error)
+	// NDJSON renders one JSON object per line: the actions followed by the summary
+	NDJSON() ([]byte, error)
+	// Markdown renders the report as Markdown tables grouped by site
+	Markdown() string
+	// HTML renders the report as a self-contained HTML page
+	HTML() ([]byte, error)
+}
+
+// ValidateFormat returns an error for an unsupported report format
+func ValidateFormat(format string) error {
+	for _, supported := range Formats {
+		if format == supported {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported report format %q (supported: %s)", format, strings.Join(Formats, ", "))
+}
+
+// CheckFormat returns an error when the report cannot be rendered in the format
+func CheckFormat(report Renderer, format string) error {
+	if err := ValidateFormat(format); err != nil {
+		return err
+	}
+	if format == FormatJUnit {
+		if _, ok := report.(JUnitRenderer); !ok {
+			return fmt.Errorf("%s reports are not available for this command", format)
+		}
+	}
+	return nil
+}
+
+// Write renders a report in the given format to w
+func Write(w io.Writer, report Renderer, format string) error {
+	var data []byte
+	var err error
+	switch format {
+	case FormatText, "":
+		data = []byte(report.Report() + "\n")
+	case FormatJSON:
+		data, err = report.JSON()
+	case FormatNDJSON:
+		data, err = report.NDJSON()
+	case FormatMarkdown:
+		data = []byte(report.Markdown())
+	case FormatHTML:
+		data, err = report.HTML()
+	default:
+		if err = CheckFormat(report, format); err == nil {
+			data, err = report.(JUnitRenderer).JUnit()
+		}
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Report types identifying JSON documents and NDJSON lines
+const (
+	typeMakeReport    = "make_report"
+	typeMakeAction    = "make_action"
+	typeMakeSummary   = "make_summary"
+	typeConfigFailure = "make_config_failure"
+	typeMkconfReport  = "mkconf_report"
+	typeMkconfAction  = "mkconf_action"
+	typeMkconfSummary = "mkconf_summary"
+)
+
+// Warm-up step statuses in JSON reports
+const (
+	stepSuccess = "success"
+	stepFailed  = "failed"
+	stepSkipped = "skipped"
+)
+
+type jsonHeader struct {
+	SchemaVersion int    `json:"schema_version"`
+	Type          string `json:"type"`
+}
+
+type jsonMakeReport struct {
+	jsonHeader
+	Summary        jsonMakeSummary     `json:"summary"`
+	Actions        []jsonMakeAction    `json:"actions"`
+	ConfigFailures []jsonConfigFailure `json:"config_failures,omitempty"`
+}
+
+type jsonConfigFailure struct {
+	Config string `json:"config"`
+	Error  string `json:"error"`
+}
+
+type jsonMakeSummary struct {
+	Total      int   `json:"total"`
+	Successful int   `json:"successful"`
+	Failed     int   `json:"failed"`
+	DurationMS int64 `json:"duration_ms"`
+}
+
+type jsonMakeAction struct {
+	Repository     string           `json:"repository"`
+	Site           string           `json:"site,omitempty"`
+	Path           string           `json:"path,omitempty"`
+	Kind           string           `json:"kind,omitempty"`
+	Memo           string           `json:"memo,omitempty"`
+	Success        bool             `json:"success"`
+	Error          string           `json:"error,omitempty"`
+	StartedAt      *time.Time       `json:"started_at,omitempty"`
+	DurationMS     int64            `json:"duration_ms"`
+	WarmUp         []jsonWarmUpStep `json:"warm_up,omitempty"`
+	WarmUpSkipped  string           `json:"warm_up_skipped,omitempty"`
+	WarmUpReverted []string         `json:"warm_up_reverted,omitempty"`
+}
+
+type jsonWarmUpStep struct {
+	Step       string `json:"step"`
+	Status     string `json:"status"`
+	DurationMS int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	Output     string `json:"output,omitempty"`
+}
+
+type jsonMkconfReport struct {
+	jsonHeader
+	Summary jsonMkconfSummary  `json:"summary"`
+	Actions []jsonMkconfAction `json:"actions"`
+}
+
+type jsonMkconfSummary struct {
+	Total       int `json:"total"`
+	WithOrigin  int `json:"with_origin"`
+	Uncommitted int `json:"uncommitted"`
+	Unmerged    int `json:"unmerged"`
+}
+
+type jsonMkconfAction struct {
+	Path        string     `json:"path"`
+	Site        string     `json:"site,omitempty"`
+	Kind        string     `json:"kind,omitempty"`
+	Origin      string     `json:"origin,omitempty"`
+	HasOrigin   bool       `json:"has_origin"`
+	Uncommitted bool       `json:"uncommitted"`
+	Unmerged    bool       `json:"unmerged"`
+	ScannedAt   *time.Time `json:"scanned_at,omitempty"`
+}
+
+// JSON renders the processing report as a single JSON document
+func (mr *MakeReport) JSON() ([]byte, error) {
+	actions, summary := mr.jsonActions()
+	return marshalIndent(jsonMakeReport{
+		jsonHeader:     jsonHeader{SchemaVersion: SchemaVersion, Type: typeMakeReport},
+		Summary:        summary,
+		Actions:        actions,
+		ConfigFailures: mr.jsonConfigFailures(),
+	})
+}
+
+// NDJSON renders one line per processed repository and per failed configuration file,
+// followed by a summary line
+func (mr *MakeReport) NDJSON() ([]byte, error) {
+	actions, summary := mr.jsonActions()
+	failures := mr.jsonConfigFailures()
+	lines := make([]interface{}, 0, len(actions)+len(failures)+1)
+	for _, action := range actions {
+		lines = append(lines, struct {
+			jsonHeader
+			jsonMakeAction
+		}{jsonHeader{SchemaVersion, typeMakeAction}, action})
+	}
+	for _, failure := range failures {
+		lines = append(lines, struct {
+			jsonHeader
+			jsonConfigFailure
+		}{jsonHeader{SchemaVersion, typeConfigFailure}, failure})
+	}
+	lines = append(lines, struct {
+		jsonHeader
+		jsonMakeSummary
+	}{jsonHeader{SchemaVersion, typeMakeSummary}, summary})
+	return marshalLines(lines)
+}
+
+// jsonActions converts the actions of the report and computes its summary
+func (mr *MakeReport) jsonActions() ([]jsonMakeAction, jsonMakeSummary) {
+	actions := make([]jsonMakeAction, 0, len(mr.Actions))
+	summary := jsonMakeSummary{Total: len(mr.Actions)}
+	for _, action := range mr.Actions {
+		if action.Success {
+			summary.Successful++
+		} else {
+			summary.Failed++
+		}
+		summary.DurationMS += action.Duration.Milliseconds()
+
+		converted := jsonMakeAction{
+			Repository:     action.Repository,
+			Site:           action.Site,
+			Path:           action.Path,
+			Kind:           action.Kind,
+			Memo:           action.Memo,
+			Success:        action.Success,
+			Error:          action.Error,
+			StartedAt:      optionalTime(action.Time),
+			DurationMS:     action.Duration.Milliseconds(),
+			WarmUpSkipped:  action.WarmUpSkipped,
+			WarmUpReverted: action.WarmUpReverted,
+		}
+		for _, step := range action.WarmUp {
+			converted.WarmUp = append(converted.WarmUp, jsonWarmUpResult(step))
+		}
+		actions = append(actions, converted)
+	}
+	return actions, summary
+}
+
+// jsonConfigFailures converts the configuration files that could not be processed
+func (mr *MakeReport) jsonConfigFailures() []jsonConfigFailure {
+	var failures []jsonConfigFailure
+	for _, failure := range mr.ConfigFailures {
+		failures = append(failures, jsonConfigFailure{Config: failure.Config, Error: failure.Error})
+	}
+	return failures
+}
+
+// jsonWarmUpResult converts a warm-up step, keeping the tail of its output
+func jsonWarmUpResult(step WarmUpResult) jsonWarmUpStep {
+	converted := jsonWarmUpStep{
+		Step:       step.Step,
+		Status:     stepSuccess,
+		DurationMS: step.Duration.Milliseconds(),
+		Output:     strings.Join(outputTail(step.Output), "\n"),
+	}
+	switch {
+	case step.Skipped != "":
+		converted.Status = stepSkipped
+		converted.Reason = step.Skipped
+	case !step.Success:
+		converted.Status = stepFailed
+		converted.Error = step.Error
+	}
+	return converted
+}
+
+// JSON renders the discovery report as a single JSON document
+func (mr *MkconfReport) JSON() ([]byte, error) {
+	actions, summary := mr.jsonActions()
+	return marshalIndent(jsonMkconfReport{
+		jsonHeader: jsonHeader{SchemaVersion: SchemaVersion, Type: typeMkconfReport},
+		Summary:    summary,
+		Actions:    actions,
+	})
+}
+
+// NDJSON renders one line per discovered repository followed by a summary line
+func (mr *MkconfReport) NDJSON() ([]byte, error) {
+	actions, summary := mr.jsonActions()
+	lines := make([]interface{}, 0, len(actions)+1)
+	for _, action := range actions {
+		lines = append(lines, struct {
+			jsonHeader
+			jsonMkconfAction
+		}{jsonHeader{SchemaVersion, typeMkconfAction}, action})
+	}
+	lines = append(lines, struct {
+		jsonHeader
+		jsonMkconfSummary
+	}{jsonHeader{SchemaVersion, typeMkconfSummary}, summary})
+	return marshalLines(lines)
+}
+
+// jsonActions converts the actions of the report and computes its summary
+func (mr *MkconfReport) jsonActions() ([]jsonMkconfAction, jsonMkconfSummary) {
+	actions := make([]jsonMkconfAction, 0, len(mr.Actions))
+	summary := jsonMkconfSummary{Total: len(mr.Actions)}
+	for _, action := range mr.Actions {
+		if action.HasOrigin {
+			summary.WithOrigin++
+		}
+		if action.Uncommitted {
+			summary.Uncommitted++
+		}
+		if action.Unmerged {
+			summary.Unmerged++
+		}
+		actions = append(actions, jsonMkconfAction{
+			Path:        action.Path,
+			Site:        action.Site,
+			Kind:        action.Kind,
+			Origin:      action.Origin,
+			HasOrigin:   action.HasOrigin,
+			Uncommitted: action.Uncommitted,
+			Unmerged:    action.Unmerged,
+			ScannedAt:   optionalTime(action.Time),
+		})
+	}
+	return actions, summary
+}
+
+// optionalTime omits unset times from JSON reports
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// marshalIndent encodes a JSON document followed by a newline
+func marshalIndent(v interface{}) ([]byte, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode report: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// marshalLines encodes each value as one line of JSON
+func marshalLines(values []interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, value := range values {
+		if err := encoder.Encode(value); err != nil {
+			return nil, fmt.Errorf("failed to encode report: %w", err)
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,229 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sampleMakeReport() *MakeReport {
+	start := time.Date(2025, 6, 2, 10, 0, 0, 0, time.UTC)
+	return &MakeReport{Actions: []*MakeAction{
+		{
+			Time:       start,
+			Repository: "team/api",
+			Path:       "/work/api",
+			Kind:       ActionClone,
+			Duration:   1500 * time.Millisecond,
+			Success:    true,
+			WarmUp: []WarmUpResult{
+				{Step: "go", Duration: time.Second, Success: true, Output: "quiet"},
+				{Step: "make bootstrap", Success: false, Error: "exit status 2", Output: "boom"},
+				{Step: "rust", Success: true, Skipped: "missing toolchain cargo: not found on PATH"},
+			},
+			WarmUpReverted: []string{"go.sum"},
+		},
+		{
+			Time:       start,
+			Repository: "team/web",
+			Path:       "/work/web",
+			Kind:       ActionUpdate,
+			Duration:   500 * time.Millisecond,
+			Error:      "failed to update repository",
+		},
+	}}
+}
+
+func TestMakeReport_JSON(t *testing.T) {
+	data, err := sampleMakeReport().JSON()
+	if err != nil {
+		t.Fatalf("JSON failed: %v", err)
+	}
+
+	var decoded struct {
+		SchemaVersion int    `json:"schema_version"`
+		Type          string `json:"type"`
+		Summary       struct {
+			Total, Successful, Failed int
+			DurationMS                int64 `json:"duration_ms"`
+		} `json:"summary"`
+		Actions []struct {
+			Repository     string
+			Path           string
+			Kind           string
+			Success        bool
+			Error          string
+			StartedAt      string   `json:"started_at"`
+			DurationMS     int64    `json:"duration_ms"`
+			WarmUpReverted []string `json:"warm_up_reverted"`
+			WarmUp         []struct {
+				Step, Status, Error, Reason, Output string
+			} `json:"warm_up"`
+		} `json:"actions"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, data)
+	}
+
+	// 版本、类型和汇总信息构成稳定的顶层结构
+	if decoded.SchemaVersion != SchemaVersion || decoded.Type != "make_report" {
+		t.Errorf("Unexpected header: %d %s", decoded.SchemaVersion, decoded.Type)
+	}
+	if decoded.Summary.Total != 2 || decoded.Summary.Successful != 1 || decoded.Summary.Failed != 1 || decoded.Summary.DurationMS != 2000 {
+		t.Errorf("Unexpected summary: %+v", decoded.Summary)
+	}
+
+	api, web := decoded.Actions[0], decoded.Actions[1]
+	if api.Path != "/work/api" || api.Kind != "clone" || api.DurationMS != 1500 || api.StartedAt != "2025-06-02T10:00:00Z" {
+		t.Errorf("Unexpected action: %+v", api)
+	}
+	if web.Kind != "update" || web.Success || web.Error != "failed to update repository" {
+		t.Errorf("Unexpected failed action: %+v", web)
+	}
+
+	// 预热步骤区分成功、失败和跳过，运行过的步骤都保留输出
+	steps := api.WarmUp
+	if len(steps) != 3 || steps[0].Status != "success" || steps[0].Output != "quiet" ||
+		steps[1].Status != "failed" || steps[1].Output != "boom" ||
+		steps[2].Status != "skipped" || !strings.Contains(steps[2].Reason, "cargo") {
+		t.Errorf("Unexpected warm-up steps: %+v", steps)
+	}
+	if len(api.WarmUpReverted) != 1 {
+		t.Errorf("Expected reverted files, got %v", api.WarmUpReverted)
+	}
+}
+
+func TestMakeReport_JSONWarmUpOutputTail(t *testing.T) {
+	var output []string
+	for i := 0; i < 30; i++ {
+		output = append(output, fmt.Sprintf("line %d", i))
+	}
+	report := &MakeReport{Actions: []*MakeAction{{
+		Repository: "team/api",
+		Success:    true,
+		WarmUp:     []WarmUpResult{{Step: "go", Success: true, Output: strings.Join(output, "\n")}},
+	}}}
+
+	data, err := report.JSON()
+	if err != nil {
+		t.Fatalf("JSON failed: %v", err)
+	}
+	var decoded struct {
+		Actions []struct {
+			WarmUp []struct{ Output string } `json:"warm_up"`
+		} `json:"actions"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, data)
+	}
+
+	// 成功步骤的输出同样保留，只截取最后几行
+	got := decoded.Actions[0].WarmUp[0].Output
+	if !strings.HasPrefix(got, "... 10 lines omitted\nline 10\n") || !strings.HasSuffix(got, "line 29") {
+		t.Errorf("Unexpected truncated output: %q", got)
+	}
+}
+
+func TestMakeReport_NDJSON(t *testing.T) {
+	data, err := sampleMakeReport().NDJSON()
+	if err != nil {
+		t.Fatalf("NDJSON failed: %v", err)
+	}
+
+	// 每个仓库一行，最后一行是汇总
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %d:\n%s", len(lines), data)
+	}
+	var types []string
+	for _, line := range lines {
+		var decoded map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &decoded); err != nil {
+			t.Fatalf("Invalid JSON line %q: %v", line, err)
+		}
+		if decoded["schema_version"] != float64(SchemaVersion) {
+			t.Errorf("Missing schema version in %q", line)
+		}
+		types = append(types, decoded["type"].(string))
+	}
+	if strings.Join(types, ",") != "make_action,make_action,make_summary" {
+		t.Errorf("Unexpected line types: %v", types)
+	}
+	if !strings.Contains(lines[0], `"repository":"team/api"`) || !strings.Contains(lines[2], `"total":2`) {
+		t.Errorf("Unexpected lines:\n%s", data)
+	}
+}
+
+func TestMakeReport_JSONConfigFailures(t *testing.T) {
+	report := &MakeReport{ConfigFailures: []ConfigFailure{{Config: "broken.toml", Error: "failed to parse TOML"}}}
+
+	data, err := report.JSON()
+	if err != nil || !strings.Contains(string(data), `"config_failures": [`) || !strings.Contains(string(data), `"config": "broken.toml"`) {
+		t.Errorf("Expected config failures in JSON (%v):\n%s", err, data)
+	}
+
+	// NDJSON 在汇总行之前为每个失败的配置文件输出一行
+	lines, err := report.NDJSON()
+	if err != nil || !strings.HasPrefix(string(lines), `{"schema_version":1,"type":"make_config_failure","config":"broken.toml"`) {
+		t.Errorf("Unexpected NDJSON (%v):\n%s", err, lines)
+	}
+	if !strings.Contains(report.Report(), "❌ FAILED configuration broken.toml") {
+		t.Errorf("Expected config failure in text report:\n%s", report.Report())
+	}
+}
+
+func TestMkconfReport_JSON(t *testing.T) {
+	report := &MkconfReport{Actions: []*MkconfAction{
+		{Path: "./api", Origin: "https://github.com/team/api.git", HasOrigin: true, Kind: "clone"},
+		{Path: "./scratch", Uncommitted: true, Unmerged: true},
+	}}
+
+	data, err := report.JSON()
+	if err != nil {
+		t.Fatalf("JSON failed: %v", err)
+	}
+	var decoded struct {
+		Type    string `json:"type"`
+		Summary struct {
+			Total       int `json:"total"`
+			WithOrigin  int `json:"with_origin"`
+			Uncommitted int `json:"uncommitted"`
+		} `json:"summary"`
+		Actions []map[string]interface{} `json:"actions"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, data)
+	}
+
+	if decoded.Type != "mkconf_report" || decoded.Summary.Total != 2 || decoded.Summary.WithOrigin != 1 || decoded.Summary.Uncommitted != 1 {
+		t.Errorf("Unexpected report: %s", data)
+	}
+	// 未设置的扫描时间不输出
+	if decoded.Actions[0]["origin"] != "https://github.com/team/api.git" || decoded.Actions[1]["scanned_at"] != nil {
+		t.Errorf("Unexpected actions: %v", decoded.Actions)
+	}
+
+	lines, err := report.NDJSON()
+	if err != nil || strings.Count(string(lines), "\n") != 3 || !strings.Contains(string(lines), `"type":"mkconf_summary"`) {
+		t.Errorf("Unexpected NDJSON (%v):\n%s", err, lines)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, sampleMakeReport(), FormatText); err != nil || !strings.Contains(buf.String(), "=== Repository Processing Report ===") {
+		t.Errorf("Unexpected text report (%v):\n%s", err, buf.String())
+	}
+
+	buf.Reset()
+	if err := Write(&buf, &MakeReport{}, FormatJSON); err != nil || !strings.Contains(buf.String(), `"actions": []`) {
+		t.Errorf("Expected an empty action list, got (%v):\n%s", err, buf.String())
+	}
+
+	if err := Write(&buf, &MakeReport{}, "xml"); err == nil || !strings.Contains(err.Error(), `unsupported report format "xml"`) {
+		t.Errorf("Expected unsupported format error, got %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package reporter
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+)
+
+// htmlTemplate renders a document as a self-contained page: styles and the script sorting
+// the tables by a clicked column are inlined
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2rem; color: #1f2328; }
+table { border-collapse: collapse; margin-bottom: 1.5rem; }
+th, td { border: 1px solid #d0d7de; padding: 0.4rem 0.7rem; text-align: left; vertical-align: top; white-space: pre-line; }
+th { background: #f6f8fa; }
+th.sortable { cursor: pointer; user-select: none; }
+th.sortable::after { content: " \2195"; color: #8c959f; }
+th[aria-sort="ascending"]::after { content: " \2191"; color: #1f2328; }
+th[aria-sort="descending"]::after { content: " \2193"; color: #1f2328; }
+tr.failed td { background: #ffebe9; }
+tr.dirty td { background: #fff8c5; }
+tr.failed td:first-child, tr.dirty td:first-child { font-weight: 600; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{- if not .Sites}}
+<p>{{.Empty}}</p>
+{{- else}}
+<table class="summary">
+<tr>{{range .Summary}}<th>{{.Label}}</th>{{end}}</tr>
+<tr>{{range .Summary}}<td>{{.Value}}</td>{{end}}</tr>
+</table>
+{{- range .Sites}}
+<h2>{{.Name}}</h2>
+<table class="site">
+<thead><tr>{{range $.Columns}}<th{{if .Sortable}} class="sortable"{{end}}>{{.Name}}</th>{{end}}</tr></thead>
+<tbody>
+{{- range .Rows}}
+<tr{{if .Highlight}} class="{{.Highlight}}"{{end}}>{{range $i, $cell := .Cells}}<td{{if (index $.Columns $i).Sortable}} data-sort="{{$cell.Sort}}"{{end}}>{{$cell.Text}}</td>{{end}}</tr>
+{{- end}}
+</tbody>
+</table>
+{{- end}}
+{{- end}}
+<script>
+document.querySelectorAll("th.sortable").forEach(function (th) {
+  th.addEventListener("click", function () {
+    var table = th.closest("table"), body = table.tBodies[0];
+    var column = Array.prototype.indexOf.call(th.parentNode.children, th);
+    var descending = th.getAttribute("aria-sort") !== "descending";
+    table.querySelectorAll("th.sortable").forEach(function (other) { other.removeAttribute("aria-sort"); });
+    th.setAttribute("aria-sort", descending ? "descending" : "ascending");
+    Array.from(body.rows).sort(function (a, b) {
+      var delta = Number(a.cells[column].dataset.sort) - Number(b.cells[column].dataset.sort);
+      return descending ? -delta : delta;
+    }).forEach(function (row) { body.appendChild(row); });
+  });
+});
+</script>
+</body>
+</html>
+`))
+
+// HTML renders the processing report as a self-contained HTML page
+func (mr *MakeReport) HTML() ([]byte, error) {
+	return renderHTML(mr.document())
+}
+
+// HTML renders the discovery report as a self-contained HTML page
+func (mr *MkconfReport) HTML() ([]byte, error) {
+	return renderHTML(mr.document())
+}
+
+// renderHTML renders a summary table followed by one table per site
+func renderHTML(doc document) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, doc); err != nil {
+		return nil, fmt.Errorf("failed to render report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,58 @@
+package reporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMakeReport_HTML(t *testing.T) {
+	report := sampleMakeReport()
+	report.Actions[0].Site = "https://github.com/"
+	report.Actions[1].Site = "https://github.com/"
+	report.Actions[1].Error = "failed to update <origin>"
+
+	data, err := report.HTML()
+	if err != nil {
+		t.Fatalf("HTML failed: %v", err)
+	}
+	page := string(data)
+
+	// 页面自包含，不引用外部资源
+	if !strings.HasPrefix(page, "<!DOCTYPE html>") || strings.Contains(page, "<link") || strings.Contains(page, "src=") {
+		t.Errorf("Expected a self-contained page:\n%s", page)
+	}
+	// 同一站点的仓库在同一张表中
+	if strings.Count(page, "<h2>") != 1 || !strings.Contains(page, "<h2>https://github.com/</h2>") {
+		t.Errorf("Expected one site table:\n%s", page)
+	}
+	// 耗时列可排序，单元格带有毫秒排序键
+	if !strings.Contains(page, `<th class="sortable">Duration</th>`) || !strings.Contains(page, `<td data-sort="1500">1.5s</td>`) {
+		t.Errorf("Expected a sortable duration column:\n%s", page)
+	}
+	// 失败的仓库被突出显示，错误信息被转义
+	if !strings.Contains(page, `<tr class="failed"><td>team/web</td>`) || !strings.Contains(page, "failed to update &lt;origin&gt;") {
+		t.Errorf("Unexpected failed row:\n%s", page)
+	}
+}
+
+func TestMkconfReport_HTML(t *testing.T) {
+	report := &MkconfReport{Actions: []*MkconfAction{
+		{Path: "./api", Origin: "https://github.com/team/api.git", HasOrigin: true, Site: "https://github.com/"},
+		{Path: "./scratch", Uncommitted: true, Unmerged: true},
+	}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, report, FormatHTML); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	page := buf.String()
+
+	// 发现报告没有可排序的列，有改动的仓库被突出显示
+	if strings.Contains(page, "data-sort") || !strings.Contains(page, `<tr class="dirty"><td>./scratch</td>`) {
+		t.Errorf("Unexpected rows:\n%s", page)
+	}
+	if !strings.Contains(page, "<h2>No origin</h2>") || !strings.Contains(page, "<td>1</td><td>1</td><td>1</td>") {
+		t.Errorf("Unexpected report:\n%s", page)
+	}
+}
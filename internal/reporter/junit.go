@@ -0,0 +1,166 @@
+package reporter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// junitSuiteName names the top-level suite of JUnit reports
+const junitSuiteName = "repoll"
+
+// junitConfigSuite names the suite of configuration files that could not be processed
+const junitConfigSuite = "configuration"
+
+// JUnitRenderer is implemented by reports that can be rendered as JUnit XML
+type JUnitRenderer interface {
+	// JUnit renders the report as JUnit XML
+	JUnit() ([]byte, error)
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// JUnit renders the processing report as JUnit XML: one test suite per site and one
+// test case per repository, failing when the repository could not be cloned or updated.
+// Configuration files that could not be processed are errors in a separate suite.
+func (mr *MakeReport) JUnit() ([]byte, error) {
+	root := junitTestSuites{Name: junitSuiteName}
+	var total time.Duration
+	var suiteTimes []time.Duration
+	suites := make(map[string]int)
+
+	for _, action := range mr.Actions {
+		index, ok := suites[action.Site]
+		if !ok {
+			index = len(root.Suites)
+			suites[action.Site] = index
+			root.Suites = append(root.Suites, junitTestSuite{Name: action.Site, Timestamp: junitTimestamp(action.Time)})
+			suiteTimes = append(suiteTimes, 0)
+		}
+		suite := &root.Suites[index]
+
+		testCase := junitTestCase{
+			ClassName: action.Site,
+			Name:      action.Repository,
+			Time:      junitSeconds(action.Duration),
+			SystemOut: junitSystemOut(action),
+		}
+		if !action.Success {
+			testCase.Failure = &junitFailure{
+				Message: firstLine(action.Error),
+				Type:    action.Kind,
+				Text:    action.Error,
+			}
+			suite.Failures++
+			root.Failures++
+		}
+
+		suite.Cases = append(suite.Cases, testCase)
+		suite.Tests++
+		suiteTimes[index] += action.Duration
+		root.Tests++
+		total += action.Duration
+	}
+	for i := range root.Suites {
+		root.Suites[i].Time = junitSeconds(suiteTimes[i])
+	}
+	root.Time = junitSeconds(total)
+
+	if len(mr.ConfigFailures) > 0 {
+		suite := junitTestSuite{Name: junitConfigSuite, Time: junitSeconds(0)}
+		for _, failure := range mr.ConfigFailures {
+			suite.Cases = append(suite.Cases, junitTestCase{
+				ClassName: junitConfigSuite,
+				Name:      failure.Config,
+				Time:      junitSeconds(0),
+				Error: &junitFailure{
+					Message: firstLine(failure.Error),
+					Type:    junitConfigSuite,
+					Text:    failure.Error,
+				},
+			})
+			suite.Tests++
+			suite.Errors++
+		}
+		root.Suites = append(root.Suites, suite)
+		root.Tests += suite.Tests
+		root.Errors += suite.Errors
+	}
+
+	data, err := xml.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode report: %w", err)
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// junitSystemOut describes the action kind, path and warm-up of a repository
+func junitSystemOut(action *MakeAction) string {
+	var out strings.Builder
+	if action.Kind != "" {
+		out.WriteString(fmt.Sprintf("%s %s\n", action.Kind, action.Path))
+	}
+	if action.WarmUpSkipped != "" {
+		out.WriteString(fmt.Sprintf("Warm-up skipped: %s\n", action.WarmUpSkipped))
+	}
+	for _, step := range action.WarmUp {
+		writeWarmUpResult(&out, step)
+	}
+	if len(action.WarmUpReverted) > 0 {
+		out.WriteString(fmt.Sprintf("Warm-up changes reverted: %s\n", strings.Join(action.WarmUpReverted, ", ")))
+	}
+	return out.String()
+}
+
+// junitSeconds formats a duration as JUnit seconds with millisecond precision
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// junitTimestamp formats the start of a suite, or "" when it is unknown
+func junitTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02T15:04:05")
+}
+
+// firstLine returns the first line of a multi-line message
+func firstLine(message string) string {
+	line, _, _ := strings.Cut(message, "\n")
+	return line
+}
@@ -0,0 +1,136 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+type parsedJUnit struct {
+	Tests    int    `xml:"tests,attr"`
+	Failures int    `xml:"failures,attr"`
+	Time     string `xml:"time,attr"`
+	Suites   []struct {
+		Name     string `xml:"name,attr"`
+		Tests    int    `xml:"tests,attr"`
+		Failures int    `xml:"failures,attr"`
+		Time     string `xml:"time,attr"`
+		Cases    []struct {
+			ClassName string `xml:"classname,attr"`
+			Name      string `xml:"name,attr"`
+			Time      string `xml:"time,attr"`
+			Failure   *struct {
+				Message string `xml:"message,attr"`
+				Type    string `xml:"type,attr"`
+				Text    string `xml:",chardata"`
+			} `xml:"failure"`
+			SystemOut string `xml:"system-out"`
+		} `xml:"testcase"`
+	} `xml:"testsuite"`
+}
+
+func TestMakeReport_JUnit(t *testing.T) {
+	report := sampleMakeReport()
+	report.Actions[0].Site = "https://github.com/"
+	report.Actions[1].Site = "https://git.company.com/"
+	report.Actions[1].Error = "failed to update repository: exit status 128\nfatal: could not read from remote"
+	report.Actions = append(report.Actions, &MakeAction{
+		Repository: "team/docs",
+		Site:       "https://github.com/",
+		Kind:       ActionClone,
+		Duration:   250 * time.Millisecond,
+		Success:    true,
+	})
+
+	data, err := report.JUnit()
+	if err != nil {
+		t.Fatalf("JUnit failed: %v", err)
+	}
+	if !strings.HasPrefix(string(data), xml.Header) {
+		t.Errorf("Missing XML header:\n%s", data)
+	}
+	var decoded parsedJUnit
+	if err := xml.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Invalid XML: %v\n%s", err, data)
+	}
+
+	// 每个站点一个测试套件，按首次出现的顺序排列
+	if decoded.Tests != 3 || decoded.Failures != 1 || decoded.Time != "2.250" || len(decoded.Suites) != 2 {
+		t.Fatalf("Unexpected test suites:\n%s", data)
+	}
+	github, company := decoded.Suites[0], decoded.Suites[1]
+	if github.Name != "https://github.com/" || github.Tests != 2 || github.Failures != 0 || github.Time != "1.750" {
+		t.Errorf("Unexpected suite: %+v", github)
+	}
+	if company.Name != "https://git.company.com/" || company.Tests != 1 || company.Failures != 1 {
+		t.Errorf("Unexpected suite: %+v", company)
+	}
+
+	// 成功的仓库没有 failure，输出中带有预热步骤
+	api := github.Cases[0]
+	if api.ClassName != "https://github.com/" || api.Name != "team/api" || api.Time != "1.500" || api.Failure != nil {
+		t.Errorf("Unexpected test case: %+v", api)
+	}
+	if !strings.Contains(api.SystemOut, "clone /work/api") || !strings.Contains(api.SystemOut, "go.sum") {
+		t.Errorf("Unexpected system-out: %q", api.SystemOut)
+	}
+
+	// 失败的仓库以错误首行作为消息，完整错误作为正文
+	web := company.Cases[0]
+	if web.Failure == nil || web.Failure.Message != "failed to update repository: exit status 128" ||
+		web.Failure.Type != ActionUpdate || !strings.Contains(web.Failure.Text, "fatal: could not read from remote") {
+		t.Errorf("Unexpected failure: %+v", web.Failure)
+	}
+}
+
+func TestMakeReport_JUnitConfigFailures(t *testing.T) {
+	report := &MakeReport{ConfigFailures: []ConfigFailure{
+		{Config: "missing.toml", Error: "configuration file not found"},
+		{Config: "broken.toml", Error: "failed to parse TOML: line 1\nexpected ]"},
+	}}
+
+	data, err := report.JUnit()
+	if err != nil {
+		t.Fatalf("JUnit failed: %v", err)
+	}
+	var decoded struct {
+		Tests  int `xml:"tests,attr"`
+		Errors int `xml:"errors,attr"`
+		Suites []struct {
+			Name   string `xml:"name,attr"`
+			Errors int    `xml:"errors,attr"`
+			Cases  []struct {
+				Name  string `xml:"name,attr"`
+				Error *struct {
+					Message string `xml:"message,attr"`
+				} `xml:"error"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	if err := xml.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Invalid XML: %v\n%s", err, data)
+	}
+
+	// 无法处理的配置文件作为错误出现，CI 不会把失败的运行当作通过
+	if decoded.Tests != 2 || decoded.Errors != 2 || len(decoded.Suites) != 1 || decoded.Suites[0].Name != "configuration" || decoded.Suites[0].Errors != 2 {
+		t.Fatalf("Unexpected report:\n%s", data)
+	}
+	broken := decoded.Suites[0].Cases[1]
+	if broken.Name != "broken.toml" || broken.Error == nil || broken.Error.Message != "failed to parse TOML: line 1" {
+		t.Errorf("Unexpected test case: %+v", broken)
+	}
+}
+
+func TestWrite_JUnit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, &MakeReport{}, FormatJUnit); err != nil || !strings.Contains(buf.String(), `<testsuites name="repoll" tests="0" failures="0" errors="0" time="0.000"></testsuites>`) {
+		t.Errorf("Expected an empty JUnit report, got (%v):\n%s", err, buf.String())
+	}
+
+	// 发现报告不支持 JUnit
+	if err := Write(&buf, &MkconfReport{}, FormatJUnit); err == nil || !strings.Contains(err.Error(), "not available") {
+		t.Errorf("Expected junit format error, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package reporter
+
+import "strings"
+
+// markdownCell escapes a table cell, keeping line breaks as <br>
+var markdownCell = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>")
+
+// Markdown renders the processing report as Markdown tables grouped by site
+func (mr *MakeReport) Markdown() string {
+	return renderMarkdown(mr.document())
+}
+
+// Markdown renders the discovery report as Markdown tables grouped by site
+func (mr *MkconfReport) Markdown() string {
+	return renderMarkdown(mr.document())
+}
+
+// renderMarkdown renders a summary table followed by one table per site. Highlighted rows have
+// their first cell in bold.
+func renderMarkdown(doc document) string {
+	var out strings.Builder
+	out.WriteString("# " + doc.Title + "\n\n")
+
+	if len(doc.Sites) == 0 {
+		out.WriteString(doc.Empty + "\n")
+		return out.String()
+	}
+
+	labels := make([]string, 0, len(doc.Summary))
+	values := make([]string, 0, len(doc.Summary))
+	for _, stat := range doc.Summary {
+		labels = append(labels, stat.Label)
+		values = append(values, stat.Value)
+	}
+	writeMarkdownRow(&out, labels)
+	writeMarkdownSeparator(&out, len(labels))
+	writeMarkdownRow(&out, values)
+
+	headers := make([]string, 0, len(doc.Columns))
+	for _, column := range doc.Columns {
+		headers = append(headers, column.Name)
+	}
+	for _, site := range doc.Sites {
+		out.WriteString("\n## " + site.Name + "\n\n")
+		writeMarkdownRow(&out, headers)
+		writeMarkdownSeparator(&out, len(headers))
+		for _, row := range site.Rows {
+			cells := make([]string, 0, len(row.Cells))
+			for _, cell := range row.Cells {
+				cells = append(cells, cell.Text)
+			}
+			if row.Highlight != "" && cells[0] != "" {
+				cells[0] = "**" + cells[0] + "**"
+			}
+			writeMarkdownRow(&out, cells)
+		}
+	}
+	return out.String()
+}
+
+// writeMarkdownRow appends one table row
+func writeMarkdownRow(out *strings.Builder, cells []string) {
+	out.WriteString("|")
+	for _, cell := range cells {
+		out.WriteString(" " + markdownCell.Replace(cell) + " |")
+	}
+	out.WriteString("\n")
+}
+
+// writeMarkdownSeparator appends the line separating the header from the rows
+func writeMarkdownSeparator(out *strings.Builder, columns int) {
+	out.WriteString("|" + strings.Repeat(" --- |", columns) + "\n")
+}
@@ -0,0 +1,70 @@
+package reporter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMakeReport_Markdown(t *testing.T) {
+	report := sampleMakeReport()
+	report.Actions[0].Site = "https://github.com/"
+	report.Actions[1].Site = "https://git.company.com/"
+	report.Actions[1].Error = "failed to update repository\nfatal: a | b"
+	markdown := report.Markdown()
+
+	// 汇总表在前，每个站点一张表
+	for _, expected := range []string{
+		"# Repository Processing Report\n",
+		"| 2 | 1 | 1 | 2s |\n",
+		"\n## https://github.com/\n",
+		"\n## https://git.company.com/\n",
+		"| team/api | clone | ✅ success | 1.5s | 🔥 go (1s)<br>quiet<br>⚠️ make bootstrap (0s): exit status 2<br>boom<br>⏭️ rust skipped: ",
+		"Warm-up changes reverted: go.sum |\n",
+	} {
+		if !strings.Contains(markdown, expected) {
+			t.Errorf("Expected %q in report:\n%s", expected, markdown)
+		}
+	}
+
+	// 失败的仓库加粗，单元格中的竖线和换行被转义
+	if !strings.Contains(markdown, `| **team/web** | update | ❌ failed | 500ms |  | failed to update repository<br>fatal: a \| b |`) {
+		t.Errorf("Unexpected failed row:\n%s", markdown)
+	}
+}
+
+func TestMakeReport_MarkdownConfigFailures(t *testing.T) {
+	report := &MakeReport{ConfigFailures: []ConfigFailure{{Config: "broken.toml", Error: "failed to parse TOML"}}}
+	markdown := report.Markdown()
+
+	// 无法处理的配置文件单独列出
+	if !strings.Contains(markdown, "| 0 | 0 | 0 | 0s | 1 |\n") || !strings.Contains(markdown, "\n## Failed configurations\n") ||
+		!strings.Contains(markdown, "| **broken.toml** |  | ❌ failed | 0s |  | failed to parse TOML |\n") {
+		t.Errorf("Unexpected report:\n%s", markdown)
+	}
+}
+
+func TestMkconfReport_Markdown(t *testing.T) {
+	report := &MkconfReport{Actions: []*MkconfAction{
+		{Path: "./api", Origin: "https://github.com/team/api.git", HasOrigin: true, Kind: "clone", Site: "https://github.com/"},
+		{Path: "./web", Origin: "https://github.com/team/web.git", HasOrigin: true, Kind: "clone", Site: "https://github.com/", Unmerged: true},
+		{Path: "./scratch", Kind: "clone", Uncommitted: true},
+	}}
+	markdown := report.Markdown()
+
+	// 有未提交或未合并改动的仓库被突出显示，没有 origin 的仓库单独成组
+	for _, expected := range []string{
+		"| 3 | 2 | 1 | 1 |\n",
+		"| ./api | clone | https://github.com/team/api.git | clean |\n",
+		"| **./web** | clone | https://github.com/team/web.git | ⚠️ unmerged |\n",
+		"\n## No origin\n",
+		"| **./scratch** | clone |  | ⚠️ uncommitted |\n",
+	} {
+		if !strings.Contains(markdown, expected) {
+			t.Errorf("Expected %q in report:\n%s", expected, markdown)
+		}
+	}
+
+	if got := (&MkconfReport{}).Markdown(); got != "# Repository Discovery Report\n\nNo repositories discovered.\n" {
+		t.Errorf("Unexpected empty report: %q", got)
+	}
+}
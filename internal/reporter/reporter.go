@@ -10,8 +10,22 @@ import (
 // MakeReport represents a report for repository processing operations
 type MakeReport struct {
 	Actions []*MakeAction
+	// ConfigFailures lists configuration files that could not be loaded or processed
+	ConfigFailures []ConfigFailure
 }
 
+// ConfigFailure records a configuration file whose repositories were not processed
+type ConfigFailure struct {
+	Config string
+	Error  string
+}
+
+// Kinds of repository processing actions
+const (
+	ActionClone  = "clone"
+	ActionUpdate = "update"
+)
+
 // MakeAction represents a single repository processing action
 type MakeAction struct {
 	Time       time.Time
@@ -20,6 +34,29 @@ type MakeAction struct {
 	Success    bool
 	Error      string
 	Memo       string
+	// Site is the remote prefix of the site the repository belongs to
+	Site string
+	// Path is the local directory of the repository
+	Path string
+	// Kind is ActionClone or ActionUpdate
+	Kind string
+	// WarmUp holds the warm-up steps run for the repository
+	WarmUp []WarmUpResult
+	// WarmUpSkipped explains why the warm-up was skipped
+	WarmUpSkipped string
+	// WarmUpReverted lists the tracked files modified by the warm-up and restored afterwards
+	WarmUpReverted []string
+}
+
+// WarmUpResult represents one warm-up step of a repository
+type WarmUpResult struct {
+	Step     string
+	Duration time.Duration
+	Success  bool
+	Output   string
+	Error    string
+	// Skipped explains why the step did not run, e.g. a missing toolchain; skipped steps are not failures
+	Skipped string
 }
 
 // MkconfReport represents a report for configuration generation operations
@@ -35,17 +72,26 @@ type MkconfAction struct {
 	HasOrigin   bool
 	Uncommitted bool
 	Unmerged    bool
+	// Kind is the repository kind (clone, worktree, submodule or bare)
+	Kind string
+	// Site is the remote prefix of the origin, empty without an origin
+	Site string
 }
 
 // Report generates a formatted string report of repository operations
 func (mr *MakeReport) Report() string {
-	if len(mr.Actions) == 0 {
+	if len(mr.Actions) == 0 && len(mr.ConfigFailures) == 0 {
 		return "No actions performed."
 	}
 
 	var report strings.Builder
 	report.WriteString("=== Repository Processing Report ===\n\n")
 
+	for _, failure := range mr.ConfigFailures {
+		report.WriteString(fmt.Sprintf("❌ FAILED configuration %s\n", failure.Config))
+		report.WriteString(fmt.Sprintf("   ❗ Error: %s\n\n", failure.Error))
+	}
+
 	successCount := 0
 	var totalDuration time.Duration
 
@@ -68,6 +114,18 @@ func (mr *MakeReport) Report() string {
 		if !action.Success && action.Error != "" {
 			report.WriteString(fmt.Sprintf("   ❗ Error: %s\n", action.Error))
 		}
+
+		if action.WarmUpSkipped != "" {
+			report.WriteString(fmt.Sprintf("   ⏭️  Warm-up skipped: %s\n", action.WarmUpSkipped))
+		}
+
+		for _, step := range action.WarmUp {
+			writeWarmUpResult(&report, step)
+		}
+
+		if len(action.WarmUpReverted) > 0 {
+			report.WriteString(fmt.Sprintf("   ↩️  Warm-up changes reverted: %s\n", strings.Join(action.WarmUpReverted, ", ")))
+		}
 		
 		report.WriteString("\n")
 	}
@@ -76,11 +134,53 @@ func (mr *MakeReport) Report() string {
 	report.WriteString(fmt.Sprintf("Total repositories: %d\n", len(mr.Actions)))
 	report.WriteString(fmt.Sprintf("Successful: %d\n", successCount))
 	report.WriteString(fmt.Sprintf("Failed: %d\n", len(mr.Actions)-successCount))
+	if len(mr.ConfigFailures) > 0 {
+		report.WriteString(fmt.Sprintf("Failed configurations: %d\n", len(mr.ConfigFailures)))
+	}
 	report.WriteString(fmt.Sprintf("Total time: %v\n", totalDuration))
 
 	return report.String()
 }
 
+// maxReportOutputLines limits how much output of a warm-up step is shown
+const maxReportOutputLines = 20
+
+// outputTail returns the last maxReportOutputLines non-empty lines of a warm-up step's output,
+// starting with a line counting the omitted ones
+func outputTail(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > maxReportOutputLines {
+		omitted := len(lines) - maxReportOutputLines
+		lines = append([]string{fmt.Sprintf("... %d lines omitted", omitted)}, lines[omitted:]...)
+	}
+	return lines
+}
+
+// writeWarmUpResult appends a warm-up step to a report, with the tail of its output
+func writeWarmUpResult(report *strings.Builder, step WarmUpResult) {
+	if step.Skipped != "" {
+		report.WriteString(fmt.Sprintf("   ⏭️  Warm-up %s skipped: %s\n", step.Step, step.Skipped))
+		return
+	}
+
+	status := "🔥"
+	if !step.Success {
+		status = "⚠️"
+	}
+	report.WriteString(fmt.Sprintf("   %s Warm-up %s (took %v)\n", status, step.Step, step.Duration))
+	if !step.Success && step.Error != "" {
+		report.WriteString(fmt.Sprintf("      ❗ %s\n", step.Error))
+	}
+	for _, line := range outputTail(step.Output) {
+		report.WriteString("      " + line + "\n")
+	}
+}
+
 // Report generates a formatted string report of repository discovery
 func (mr *MkconfReport) Report() string {
 	if len(mr.Actions) == 0 {
@@ -115,7 +215,11 @@ func (mr *MkconfReport) Report() string {
 			unmergedCount++
 		}
 
-		report.WriteString(fmt.Sprintf("%s %s\n", status, action.Path))
+		if action.Kind != "" && action.Kind != "clone" {
+			report.WriteString(fmt.Sprintf("%s %s (%s)\n", status, action.Path, action.Kind))
+		} else {
+			report.WriteString(fmt.Sprintf("%s %s\n", status, action.Path))
+		}
 		
 		if action.HasOrigin && action.Origin != "" {
 			report.WriteString(fmt.Sprintf("   🔗 %s\n", action.Origin))
@@ -311,4 +311,61 @@ func TestMakeReport_Report_LargeOutput(t *testing.T) {
 			t.Errorf("Expected repository %s in output", repoName)
 		}
 	}
-} 
\ No newline at end of file
+} 
+func TestMakeReport_Report_WarmUp(t *testing.T) {
+	var output []string
+	for i := 0; i < 30; i++ {
+		output = append(output, fmt.Sprintf("line %d", i))
+	}
+
+	report := &MakeReport{Actions: []*MakeAction{{
+		Repository: "team/api",
+		Success:    true,
+		WarmUp: []WarmUpResult{
+			{Step: "go", Duration: time.Second, Success: true, Output: "quiet"},
+			{Step: "make bootstrap", Success: false, Error: "exit status 2", Output: strings.Join(output, "\n")},
+			{Step: "rust", Success: true, Skipped: "missing toolchain cargo: not found on PATH"},
+		},
+	}}}
+
+	result := report.Report()
+
+	// 每个步骤显示输出的最后几行，失败步骤还显示错误
+	// 缺少工具链的步骤显示为跳过
+	for _, expected := range []string{"Warm-up go (took 1s)", "quiet", "Warm-up make bootstrap", "exit status 2", "10 lines omitted", "line 29", "Warm-up rust skipped: missing toolchain cargo"} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected report to contain %q, got:\n%s", expected, result)
+		}
+	}
+	for _, unexpected := range []string{"line 9\n"} {
+		if strings.Contains(result, unexpected) {
+			t.Errorf("Expected report not to contain %q", unexpected)
+		}
+	}
+}
+
+func TestMakeReport_Report_WarmUpSkipped(t *testing.T) {
+	report := &MakeReport{Actions: []*MakeAction{{
+		Repository:    "team/api",
+		Success:       true,
+		WarmUpSkipped: "dependency manifests and tool versions unchanged",
+	}}}
+
+	// 跳过预热的原因显示在报告中
+	if result := report.Report(); !strings.Contains(result, "Warm-up skipped: dependency manifests and tool versions unchanged") {
+		t.Errorf("Expected skip reason in report, got:\n%s", result)
+	}
+}
+
+func TestMakeReport_Report_WarmUpReverted(t *testing.T) {
+	report := &MakeReport{Actions: []*MakeAction{{
+		Repository:     "team/api",
+		Success:        true,
+		WarmUpReverted: []string{"go.mod", "go.sum"},
+	}}}
+
+	// 被还原的跟踪文件显示在报告中
+	if result := report.Report(); !strings.Contains(result, "Warm-up changes reverted: go.mod, go.sum") {
+		t.Errorf("Expected reverted files in report, got:\n%s", result)
+	}
+}
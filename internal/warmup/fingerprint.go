@@ -0,0 +1,139 @@
+package warmup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// manifestFiles are the dependency manifests and lockfiles, relative to a project directory,
+// whose changes require a new warm-up
+var manifestFiles = []string{
+	"go.mod", "go.sum", "go.work", "go.work.sum",
+	"package.json", "package-lock.json", "npm-shrinkwrap.json", "yarn.lock", ".yarnrc.yml",
+	"pnpm-lock.yaml", "pnpm-workspace.yaml", "bun.lockb", "bun.lock",
+	"requirements.txt", "pyproject.toml", "setup.py", "setup.cfg", "Pipfile", "Pipfile.lock",
+	"poetry.lock", "pdm.lock", "uv.lock", "hatch.toml",
+	"Cargo.toml", "Cargo.lock",
+	"pom.xml", ".mvn/wrapper/maven-wrapper.properties",
+	"build.gradle", "build.gradle.kts", "settings.gradle", "settings.gradle.kts", "gradle.properties",
+	"gradle/libs.versions.toml", "gradle/wrapper/gradle-wrapper.properties",
+}
+
+// installDirs are created by warm-ups inside projects; deleting one requires a new warm-up
+var installDirs = []string{"node_modules", VenvDir}
+
+// VersionedProvider is implemented by providers whose warm-up depends on the installed tool version
+type VersionedProvider interface {
+	Provider
+	// ToolVersion describes the tool used for the project in dir
+	ToolVersion(dir string) string
+}
+
+// State records the last successful warm-up of a repository
+type State struct {
+	Fingerprint string    `json:"fingerprint"`
+	Time        time.Time `json:"time"`
+}
+
+// toolVersions caches tool version output for the lifetime of the process
+var toolVersions sync.Map
+
+// Fingerprint hashes the dependency manifests of the repository and its projects, the tool
+// versions of the detected providers and extra values such as custom warm-up commands.
+// An unchanged fingerprint means a new warm-up would not change anything.
+func Fingerprint(repoDir string, extra ...string) (string, error) {
+	hash := sha256.New()
+
+	var walkErr error
+	walkProjectDirs(repoDir, MaxProjectDepth, func(dir string) {
+		rel, _ := filepath.Rel(repoDir, dir)
+		for _, name := range manifestFiles {
+			file, err := os.Open(filepath.Join(dir, filepath.FromSlash(name)))
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(hash, "file %s\x00", filepath.ToSlash(filepath.Join(rel, name)))
+			if _, err := io.Copy(hash, file); err != nil && walkErr == nil {
+				walkErr = fmt.Errorf("failed to read %s: %w", name, err)
+			}
+			file.Close()
+		}
+		for _, name := range installDirs {
+			if fileExists(dir, name) {
+				fmt.Fprintf(hash, "installed %s\x00", filepath.ToSlash(filepath.Join(rel, name)))
+			}
+		}
+	})
+	if walkErr != nil {
+		return "", walkErr
+	}
+
+	for _, project := range DetectProjects(repoDir, MaxProjectDepth) {
+		if versioned, ok := project.Provider.(VersionedProvider); ok {
+			fmt.Fprintf(hash, "tool %s %s\x00", project.Provider.Name(), versioned.ToolVersion(project.Dir))
+		}
+	}
+	for _, value := range extra {
+		fmt.Fprintf(hash, "extra %s\x00", value)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// toolVersion runs a version command once per process and returns its trimmed output
+func toolVersion(command []string) string {
+	key := strings.Join(command, " ")
+	if version, ok := toolVersions.Load(key); ok {
+		return version.(string)
+	}
+
+	version := "unavailable"
+	// Some tools, e.g. java -version, print their version to stderr
+	if output, err := exec.Command(command[0], command[1:]...).CombinedOutput(); err == nil {
+		version = strings.TrimSpace(string(output))
+	}
+	toolVersions.Store(key, version)
+	return version
+}
+
+// ReadState reads a warm-up state file; a missing file yields nil
+func ReadState(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read warm-up state: %w", err)
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse warm-up state %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+// WriteState saves a warm-up state file, creating its directory
+func WriteState(path string, state State) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode warm-up state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create warm-up state directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write warm-up state: %w", err)
+	}
+	return nil
+}
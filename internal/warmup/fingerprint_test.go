@@ -0,0 +1,79 @@
+package warmup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFingerprint(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"README.md":              "docs",
+		"web/package.json":       `{"name": "web"}`,
+		"web/package-lock.json":  `{}`,
+		"web/node_modules/.keep": "",
+	})
+
+	fingerprint := func(extra ...string) string {
+		t.Helper()
+		value, err := Fingerprint(root, extra...)
+		if err != nil {
+			t.Fatalf("Fingerprint failed: %v", err)
+		}
+		return value
+	}
+
+	base := fingerprint()
+	if base != fingerprint() {
+		t.Fatal("Fingerprint is not stable")
+	}
+
+	// 非依赖文件的修改不影响指纹
+	writeFiles(t, root, map[string]string{"README.md": "changed docs"})
+	if fingerprint() != base {
+		t.Error("Changing a non-manifest file should keep the fingerprint")
+	}
+
+	if fingerprint("make bootstrap") == base {
+		t.Error("Extra values should change the fingerprint")
+	}
+
+	// 子目录中的锁文件变化会改变指纹
+	writeFiles(t, root, map[string]string{"web/package-lock.json": `{"lockfileVersion": 3}`})
+	changed := fingerprint()
+	if changed == base {
+		t.Error("Changing a lockfile should change the fingerprint")
+	}
+
+	// 删除安装目录后需要重新预热
+	if err := os.RemoveAll(filepath.Join(root, "web", "node_modules")); err != nil {
+		t.Fatalf("Failed to remove node_modules: %v", err)
+	}
+	if fingerprint() == changed {
+		t.Error("Removing node_modules should change the fingerprint")
+	}
+}
+
+func TestState_ReadWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repoll", "warmup.json")
+
+	// 状态文件不存在时返回 nil
+	state, err := ReadState(path)
+	if err != nil || state != nil {
+		t.Fatalf("Expected no state for a missing file, got %+v (%v)", state, err)
+	}
+
+	saved := State{Fingerprint: "abc", Time: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	if err := WriteState(path, saved); err != nil {
+		t.Fatalf("WriteState failed: %v", err)
+	}
+	state, err = ReadState(path)
+	if err != nil {
+		t.Fatalf("ReadState failed: %v", err)
+	}
+	if state.Fingerprint != saved.Fingerprint || !state.Time.Equal(saved.Time) {
+		t.Errorf("Unexpected state: %+v", state)
+	}
+}
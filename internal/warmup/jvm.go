@@ -0,0 +1,122 @@
+package warmup
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// gradleBuildFiles mark a Gradle project, with Groovy or Kotlin DSL
+var gradleBuildFiles = []string{"build.gradle", "build.gradle.kts", "settings.gradle", "settings.gradle.kts"}
+
+// gradleInclude matches the quoted project paths of include statements in Gradle settings
+var (
+	gradleInclude     = regexp.MustCompile(`(?m)^\s*include\b(.*)$`)
+	gradleQuotedValue = regexp.MustCompile(`["']([^"']+)["']`)
+)
+
+// isMavenProject checks if the directory contains a Maven project
+func isMavenProject(dir string) bool {
+	return fileExists(dir, "pom.xml")
+}
+
+// isGradleProject checks if the directory contains a Gradle project
+func isGradleProject(dir string) bool {
+	for _, name := range gradleBuildFiles {
+		if fileExists(dir, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// mavenCommands resolves every dependency and plugin of a Maven project, preferring the wrapper
+func mavenCommands(repoDir string) [][]string {
+	command := append(wrapperCommand(repoDir, "mvnw", "mvn"), "-B", "-q", "dependency:go-offline")
+	return [][]string{command}
+}
+
+// gradleCommands resolves the dependencies of a Gradle build and its subprojects, preferring the wrapper
+func gradleCommands(repoDir string) [][]string {
+	command := append(wrapperCommand(repoDir, "gradlew", "gradle"), "--no-daemon", "-q", "dependencies")
+	for _, project := range gradleProjects(repoDir) {
+		command = append(command, project+":dependencies")
+	}
+	return [][]string{command}
+}
+
+// wrapperCommand returns the build tool wrapper script of the project when present, or the tool itself
+func wrapperCommand(repoDir, wrapper, tool string) []string {
+	if runtime.GOOS == "windows" {
+		for _, script := range []string{wrapper + ".cmd", wrapper + ".bat"} {
+			if fileExists(repoDir, script) {
+				return []string{"." + string(filepath.Separator) + script}
+			}
+		}
+		return []string{tool}
+	}
+
+	info, err := os.Stat(filepath.Join(repoDir, wrapper))
+	if err != nil {
+		return []string{tool}
+	}
+	// Wrappers checked out without the executable bit still run through the shell
+	if info.Mode()&0111 == 0 {
+		return []string{"sh", wrapper}
+	}
+	return []string{"./" + wrapper}
+}
+
+// mavenModules reads the <modules> of a multi-module pom.xml; the reactor build covers them
+func mavenModules(dir string) []string {
+	var pom struct {
+		Modules []string `xml:"modules>module"`
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "pom.xml"))
+	if err != nil || xml.Unmarshal(data, &pom) != nil {
+		return nil
+	}
+
+	members := make([]string, 0, len(pom.Modules))
+	for _, module := range pom.Modules {
+		members = append(members, filepath.Join(dir, filepath.FromSlash(strings.TrimSpace(module))))
+	}
+	return members
+}
+
+// gradleProjects reads the project paths included by the Gradle settings, e.g. ":services:api"
+func gradleProjects(dir string) []string {
+	var data []byte
+	for _, name := range []string{"settings.gradle", "settings.gradle.kts"} {
+		if content, err := os.ReadFile(filepath.Join(dir, name)); err == nil {
+			data = content
+			break
+		}
+	}
+
+	var projects []string
+	for _, include := range gradleInclude.FindAllSubmatch(data, -1) {
+		for _, value := range gradleQuotedValue.FindAllSubmatch(include[1], -1) {
+			project := string(value[1])
+			if !strings.HasPrefix(project, ":") {
+				project = ":" + project
+			}
+			projects = append(projects, project)
+		}
+	}
+	return projects
+}
+
+// gradleMembers maps the included Gradle projects to their default directories
+func gradleMembers(dir string) []string {
+	projects := gradleProjects(dir)
+	members := make([]string, 0, len(projects))
+	for _, project := range projects {
+		path := strings.ReplaceAll(strings.TrimPrefix(project, ":"), ":", string(filepath.Separator))
+		members = append(members, filepath.Join(dir, path))
+	}
+	return members
+}
@@ -0,0 +1,72 @@
+package warmup
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMavenCommands(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"pom.xml": "<project/>"})
+
+	if !isMavenProject(dir) {
+		t.Fatal("Expected a Maven project")
+	}
+	expected := [][]string{{"mvn", "-B", "-q", "dependency:go-offline"}}
+	if got := mavenCommands(dir); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	// 优先使用项目自带的 Maven Wrapper
+	if err := os.WriteFile(filepath.Join(dir, "mvnw"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatalf("Failed to create mvnw: %v", err)
+	}
+	if got := mavenCommands(dir); got[0][0] != "./mvnw" {
+		t.Errorf("Expected the Maven wrapper, got %v", got)
+	}
+}
+
+func TestGradleCommands(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"settings.gradle.kts": "rootProject.name = \"app\"\ninclude(\":services:api\", \"web\")\n",
+		"build.gradle.kts":    "plugins { java }\n",
+		// 没有可执行权限的 wrapper 通过 sh 运行
+		"gradlew": "#!/bin/sh\n",
+	})
+
+	if !isGradleProject(dir) {
+		t.Fatal("Expected a Gradle project with Kotlin DSL")
+	}
+	expected := [][]string{{"sh", "gradlew", "--no-daemon", "-q", "dependencies", ":services:api:dependencies", ":web:dependencies"}}
+	if got := gradleCommands(dir); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestDetectProjects_JVMBuilds(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"pom.xml":                  "<project><modules><module>core</module></modules></project>",
+		"core/pom.xml":             "<project/>",
+		"tools/pom.xml":            "<project/>",
+		"android/settings.gradle":  "include ':app'\n",
+		"android/app/build.gradle": "apply plugin: 'java'\n",
+		"android/lib/build.gradle": "apply plugin: 'java'\n",
+	})
+
+	var got []string
+	for _, project := range DetectProjects(root, MaxProjectDepth) {
+		rel, _ := filepath.Rel(root, project.Dir)
+		got = append(got, project.Provider.Name()+":"+filepath.ToSlash(rel))
+	}
+
+	// 多模块 Maven 和 Gradle 构建只在根目录解析一次，未纳入构建的项目单独处理
+	expected := "maven:.,gradle:android,gradle:android/lib,maven:tools"
+	if strings.Join(got, ",") != expected {
+		t.Errorf("Unexpected projects: %v, expected %s", got, expected)
+	}
+}
@@ -0,0 +1,123 @@
+package warmup
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Node.js package managers
+const (
+	managerNpm  = "npm"
+	managerPnpm = "pnpm"
+	managerYarn = "yarn"
+	managerBun  = "bun"
+)
+
+// nodeLockfiles maps lockfiles to their package manager, in detection order
+var nodeLockfiles = []struct {
+	name    string
+	manager string
+}{
+	{"pnpm-lock.yaml", managerPnpm},
+	{"bun.lockb", managerBun},
+	{"bun.lock", managerBun},
+	{"yarn.lock", managerYarn},
+	{"package-lock.json", managerNpm},
+	{"npm-shrinkwrap.json", managerNpm},
+}
+
+// corepackManagers are the package managers corepack can provision
+var corepackManagers = map[string]bool{managerPnpm: true, managerYarn: true}
+
+// nodeCommands lists the warm-up commands for Node.js projects.
+// The package manager comes from the packageManager field of package.json, or else from
+// the lockfile; installs respect the lockfile instead of rewriting it.
+func nodeCommands(repoDir string) [][]string {
+	manager, version := packageManagerField(repoDir)
+	if manager == "" {
+		manager = lockfileManager(repoDir)
+	}
+
+	command := nodeInstallCommand(repoDir, manager, version)
+	// Corepack runs the exact version pinned by packageManager
+	if version != "" && corepackManagers[manager] {
+		if _, err := exec.LookPath("corepack"); err == nil {
+			command = append([]string{"corepack"}, command...)
+		}
+	}
+	return [][]string{command}
+}
+
+// nodeInstallCommand returns the install command of a package manager, frozen when a lockfile exists
+func nodeInstallCommand(repoDir, manager, version string) []string {
+	switch manager {
+	case managerPnpm:
+		if fileExists(repoDir, "pnpm-lock.yaml") {
+			return []string{"pnpm", "install", "--frozen-lockfile"}
+		}
+		return []string{"pnpm", "install"}
+	case managerBun:
+		if fileExists(repoDir, "bun.lockb") || fileExists(repoDir, "bun.lock") {
+			return []string{"bun", "install", "--frozen-lockfile"}
+		}
+		return []string{"bun", "install"}
+	case managerYarn:
+		if !fileExists(repoDir, "yarn.lock") {
+			return []string{"yarn", "install"}
+		}
+		if isYarnBerry(repoDir, version) {
+			return []string{"yarn", "install", "--immutable"}
+		}
+		return []string{"yarn", "install", "--frozen-lockfile"}
+	default:
+		if fileExists(repoDir, "package-lock.json") || fileExists(repoDir, "npm-shrinkwrap.json") {
+			return []string{"npm", "ci"}
+		}
+		return []string{"npm", "install"}
+	}
+}
+
+// packageManagerField reads the corepack packageManager field of package.json, e.g. "pnpm@8.15.4"
+func packageManagerField(repoDir string) (manager, version string) {
+	var pkg struct {
+		PackageManager string `json:"packageManager"`
+	}
+	data, err := os.ReadFile(filepath.Join(repoDir, "package.json"))
+	if err != nil || json.Unmarshal(data, &pkg) != nil || pkg.PackageManager == "" {
+		return "", ""
+	}
+
+	manager, version, _ = strings.Cut(pkg.PackageManager, "@")
+	version, _, _ = strings.Cut(version, "+")
+	return manager, version
+}
+
+// lockfileManager picks the package manager from the lockfile, defaulting to npm
+func lockfileManager(repoDir string) string {
+	for _, lockfile := range nodeLockfiles {
+		if fileExists(repoDir, lockfile.name) {
+			return lockfile.manager
+		}
+	}
+	return managerNpm
+}
+
+// isYarnBerry reports whether the project uses Yarn 2 or later rather than Yarn classic
+func isYarnBerry(repoDir, version string) bool {
+	if major, _, _ := strings.Cut(version, "."); major != "" {
+		if n, err := strconv.Atoi(major); err == nil {
+			return n >= 2
+		}
+	}
+	if fileExists(repoDir, ".yarnrc.yml") {
+		return true
+	}
+	// Berry lockfiles are YAML with a __metadata entry; classic lockfiles are not
+	lock, err := os.ReadFile(filepath.Join(repoDir, "yarn.lock"))
+	return err == nil && bytes.Contains(lock, []byte("__metadata:"))
+}
@@ -0,0 +1,92 @@
+package warmup
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNodeCommands(t *testing.T) {
+	// 不在 PATH 中提供 corepack，保证结果与环境无关
+	t.Setenv("PATH", t.TempDir())
+
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected []string
+	}{
+		{
+			name:     "npm without lockfile",
+			files:    map[string]string{"package.json": `{}`},
+			expected: []string{"npm", "install"},
+		},
+		{
+			name:     "npm lockfile",
+			files:    map[string]string{"package.json": `{}`, "package-lock.json": `{}`},
+			expected: []string{"npm", "ci"},
+		},
+		{
+			name:     "pnpm lockfile",
+			files:    map[string]string{"package.json": `{}`, "pnpm-lock.yaml": "lockfileVersion: '6.0'\n"},
+			expected: []string{"pnpm", "install", "--frozen-lockfile"},
+		},
+		{
+			name:     "bun lockfile",
+			files:    map[string]string{"package.json": `{}`, "bun.lockb": ""},
+			expected: []string{"bun", "install", "--frozen-lockfile"},
+		},
+		{
+			name:     "yarn classic",
+			files:    map[string]string{"package.json": `{}`, "yarn.lock": "# yarn lockfile v1\n"},
+			expected: []string{"yarn", "install", "--frozen-lockfile"},
+		},
+		{
+			name:     "yarn berry lockfile",
+			files:    map[string]string{"package.json": `{}`, "yarn.lock": "__metadata:\n  version: 6\n"},
+			expected: []string{"yarn", "install", "--immutable"},
+		},
+		{
+			name:     "yarn berry from packageManager",
+			files:    map[string]string{"package.json": `{"packageManager": "yarn@4.1.0+sha224.abc"}`, "yarn.lock": "# yarn lockfile v1\n"},
+			expected: []string{"yarn", "install", "--immutable"},
+		},
+		{
+			name:     "packageManager wins over lockfile",
+			files:    map[string]string{"package.json": `{"packageManager": "pnpm@8.15.4"}`, "package-lock.json": `{}`},
+			expected: []string{"pnpm", "install"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, test.files)
+
+			expected := [][]string{test.expected}
+			if got := nodeCommands(dir); !reflect.DeepEqual(got, expected) {
+				t.Errorf("Expected %v, got %v", expected, got)
+			}
+		})
+	}
+}
+
+func TestNodeCommands_Corepack(t *testing.T) {
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "corepack"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatalf("Failed to create fake corepack: %v", err)
+	}
+	t.Setenv("PATH", binDir)
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"package.json":   `{"packageManager": "pnpm@8.15.4"}`,
+		"pnpm-lock.yaml": "lockfileVersion: '6.0'\n",
+	})
+
+	// 声明了 packageManager 且 corepack 可用时，通过 corepack 使用固定版本
+	expected := [][]string{{"corepack", "pnpm", "install", "--frozen-lockfile"}}
+	if got := nodeCommands(dir); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,194 @@
+package warmup
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// MaxProjectDepth is how many directory levels below the repository root are searched for projects
+const MaxProjectDepth = 3
+
+// skippedProjectDirs hold dependencies, build output or fixtures rather than projects
+var skippedProjectDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	"target":       true,
+	"testdata":     true,
+}
+
+// WorkspaceProvider is implemented by providers whose projects can be workspaces.
+// Warming up a workspace root prepares its members, so members are not warmed up again.
+type WorkspaceProvider interface {
+	Provider
+	// Members returns the member directories of the workspace rooted at dir
+	Members(dir string) []string
+}
+
+// Project is a directory of a repository detected by a provider
+type Project struct {
+	Dir      string
+	Provider Provider
+}
+
+// DetectProjects finds the projects in the repository and up to maxDepth directories below it.
+// Hidden directories and dependency directories are skipped, and members of a detected
+// workspace are left to the workspace root so every project is prepared once.
+func DetectProjects(repoDir string, maxDepth int) []Project {
+	providers := Providers()
+	covered := make(map[string]map[string]bool)
+
+	var projects []Project
+	walkProjectDirs(repoDir, maxDepth, func(dir string) {
+		for _, provider := range providers {
+			if covered[provider.Name()][filepath.Clean(dir)] || !provider.Detect(dir) {
+				continue
+			}
+			projects = append(projects, Project{Dir: dir, Provider: provider})
+
+			if workspace, ok := provider.(WorkspaceProvider); ok {
+				if covered[provider.Name()] == nil {
+					covered[provider.Name()] = make(map[string]bool)
+				}
+				for _, member := range workspace.Members(dir) {
+					covered[provider.Name()][filepath.Clean(member)] = true
+				}
+			}
+		}
+	})
+
+	return projects
+}
+
+// walkProjectDirs calls fn for the repository directory and its subdirectories up to maxDepth
+// levels below it, in lexical order, skipping hidden and dependency directories
+func walkProjectDirs(repoDir string, maxDepth int, fn func(dir string)) {
+	filepath.WalkDir(repoDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(repoDir, path)
+		if err != nil {
+			return nil
+		}
+		if rel != "." {
+			if strings.HasPrefix(d.Name(), ".") || skippedProjectDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+		}
+
+		fn(path)
+
+		if rel != "." && strings.Count(filepath.ToSlash(rel), "/")+1 >= maxDepth {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+}
+
+// globMembers expands workspace member patterns relative to the workspace root
+func globMembers(root string, patterns []string) []string {
+	var members []string
+	for _, pattern := range patterns {
+		pattern = strings.TrimPrefix(strings.TrimSpace(pattern), "./")
+		if pattern == "" || strings.HasPrefix(pattern, "!") {
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(pattern)))
+		if err != nil {
+			continue
+		}
+		members = append(members, matches...)
+	}
+	return members
+}
+
+// goWorkspaceMembers reads the module directories listed by use directives in go.work
+func goWorkspaceMembers(dir string) []string {
+	file, err := os.Open(filepath.Join(dir, "go.work"))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var members []string
+	inUse := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+
+		switch {
+		case inUse && line == ")":
+			inUse = false
+			continue
+		case line == "use (":
+			inUse = true
+			continue
+		case strings.HasPrefix(line, "use "):
+			line = strings.TrimSpace(strings.TrimPrefix(line, "use "))
+		case !inUse:
+			continue
+		}
+
+		if line = strings.Trim(line, `"`); line != "" {
+			members = append(members, filepath.Join(dir, filepath.FromSlash(line)))
+		}
+	}
+	return members
+}
+
+// nodeWorkspaceMembers reads package.json workspaces (npm and yarn) and pnpm-workspace.yaml packages
+func nodeWorkspaceMembers(dir string) []string {
+	var patterns []string
+
+	if data, err := os.ReadFile(filepath.Join(dir, "package.json")); err == nil {
+		var pkg struct {
+			Workspaces json.RawMessage `json:"workspaces"`
+		}
+		if json.Unmarshal(data, &pkg) == nil && len(pkg.Workspaces) > 0 {
+			var list []string
+			var object struct {
+				Packages []string `json:"packages"`
+			}
+			if json.Unmarshal(pkg.Workspaces, &list) == nil {
+				patterns = append(patterns, list...)
+			} else if json.Unmarshal(pkg.Workspaces, &object) == nil {
+				patterns = append(patterns, object.Packages...)
+			}
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, "pnpm-workspace.yaml")); err == nil {
+		var workspace struct {
+			Packages []string `yaml:"packages"`
+		}
+		if yaml.Unmarshal(data, &workspace) == nil {
+			patterns = append(patterns, workspace.Packages...)
+		}
+	}
+
+	return globMembers(dir, patterns)
+}
+
+// cargoWorkspaceMembers reads [workspace].members from Cargo.toml
+func cargoWorkspaceMembers(dir string) []string {
+	var manifest struct {
+		Workspace struct {
+			Members []string `toml:"members"`
+		} `toml:"workspace"`
+	}
+	if _, err := toml.DecodeFile(filepath.Join(dir, "Cargo.toml"), &manifest); err != nil {
+		return nil
+	}
+	return globMembers(dir, manifest.Workspace.Members)
+}
@@ -0,0 +1,80 @@
+package warmup
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFiles creates files with their parent directories below root
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+}
+
+func TestDetectProjects_Monorepo(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.work":                           "go 1.22\n\nuse (\n\t./services/api // main service\n)\n",
+		"Cargo.toml":                        "[workspace]\nmembers = [\"crates/*\"]\n",
+		"crates/core/Cargo.toml":            "[package]\nname = \"core\"\n",
+		"services/api/go.mod":               "module example.com/api\n",
+		"services/worker/go.mod":            "module example.com/worker\n",
+		"web/package.json":                  `{"name": "web", "workspaces": ["packages/*"]}`,
+		"web/packages/ui/package.json":      `{"name": "ui"}`,
+		"web/node_modules/dep/package.json": `{"name": "dep"}`,
+		"vendor/example.com/lib/go.mod":     "module example.com/lib\n",
+		".cache/tool/go.mod":                "module example.com/tool\n",
+		"deep/a/b/c/go.mod":                 "module example.com/deep\n",
+	})
+
+	var got []string
+	for _, project := range DetectProjects(root, MaxProjectDepth) {
+		rel, _ := filepath.Rel(root, project.Dir)
+		got = append(got, project.Provider.Name()+":"+filepath.ToSlash(rel))
+	}
+
+	// 工作区成员交给工作区根目录处理，依赖目录、隐藏目录和超过深度的项目被跳过
+	expected := []string{"go:.", "rust:.", "go:services/worker", "node:web"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Unexpected projects:\n got %v\nwant %v", got, expected)
+	}
+}
+
+func TestNodeWorkspaceMembers(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"package.json":             `{"name": "root", "workspaces": {"packages": ["apps/*"]}}`,
+		"pnpm-workspace.yaml":      "packages:\n  - 'libs/*'\n  - '!libs/skip'\n",
+		"apps/site/package.json":   `{}`,
+		"libs/shared/package.json": `{}`,
+	})
+
+	// 同时支持 yarn 的对象形式 workspaces 和 pnpm-workspace.yaml
+	expected := []string{filepath.Join(root, "apps", "site"), filepath.Join(root, "libs", "shared")}
+	if got := nodeWorkspaceMembers(root); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Unexpected members:\n got %v\nwant %v", got, expected)
+	}
+}
+
+func TestGoCommands_Workspace(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"go.work": "go 1.22\n\nuse ./a\n"})
+
+	// 工作区同样只下载依赖
+	if got := goCommands(root); !reflect.DeepEqual(got, [][]string{{"go", "mod", "download"}}) {
+		t.Errorf("Unexpected workspace commands: %v", got)
+	}
+	if got := goWorkspaceMembers(root); !reflect.DeepEqual(got, []string{filepath.Join(root, "a")}) {
+		t.Errorf("Unexpected single-line use members: %v", got)
+	}
+}
@@ -0,0 +1,144 @@
+package warmup
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Provider warms up the dependencies of one project ecosystem
+type Provider interface {
+	// Name identifies the provider, e.g. "go" or "node"
+	Name() string
+	// Detect reports whether the repository uses the ecosystem
+	Detect(repoDir string) bool
+	// Commands lists the commands Run executes in the repository
+	Commands(repoDir string) [][]string
+	// Run warms up the repository
+	Run(repoDir string) error
+}
+
+// OutputProvider is a Provider whose warm-up output is captured for reports
+type OutputProvider interface {
+	Provider
+	// RunWithOutput warms up the repository like Run, returning the combined output of its commands
+	RunWithOutput(repoDir string) (string, error)
+}
+
+// commandProvider is a Provider that runs a list of commands
+type commandProvider struct {
+	name     string
+	detect   func(repoDir string) bool
+	commands func(repoDir string) [][]string
+	// members lists workspace members; nil for ecosystems without workspaces
+	members func(dir string) []string
+	// version prints the version of the tool the warm-up depends on
+	version []string
+	// tools lists the required tools; nil derives them from the commands
+	tools func(repoDir string) []Tool
+	// toolchain lists every tool the provider may require
+	toolchain []Tool
+}
+
+// NewCommandProvider creates a provider running the commands returned for detected repositories
+func NewCommandProvider(name string, detect func(repoDir string) bool, commands func(repoDir string) [][]string) Provider {
+	return &commandProvider{name: name, detect: detect, commands: commands}
+}
+
+func (p *commandProvider) Name() string {
+	return p.name
+}
+
+func (p *commandProvider) Detect(repoDir string) bool {
+	return p.detect(repoDir)
+}
+
+func (p *commandProvider) Commands(repoDir string) [][]string {
+	return p.commands(repoDir)
+}
+
+func (p *commandProvider) Run(repoDir string) error {
+	return RunCommands(repoDir, p.Commands(repoDir))
+}
+
+func (p *commandProvider) RunWithOutput(repoDir string) (string, error) {
+	var output strings.Builder
+	for _, command := range p.Commands(repoDir) {
+		result := runStep(repoDir, Step{Command: command})
+		output.WriteString(result.Output)
+		if result.Err != nil {
+			return output.String(), fmt.Errorf("%s failed: %w", result.Name, result.Err)
+		}
+	}
+	return output.String(), nil
+}
+
+func (p *commandProvider) ToolVersion(string) string {
+	if len(p.version) == 0 {
+		return ""
+	}
+	return toolVersion(p.version)
+}
+
+func (p *commandProvider) RequiredTools(repoDir string) []Tool {
+	if p.tools == nil {
+		return commandTools(p.Commands(repoDir))
+	}
+	return p.tools(repoDir)
+}
+
+func (p *commandProvider) Toolchain() []Tool {
+	return p.toolchain
+}
+
+func (p *commandProvider) Members(dir string) []string {
+	if p.members == nil {
+		return nil
+	}
+	return p.members(dir)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = []Provider{
+		&commandProvider{name: "go", detect: isGoWorkspaceOrProject, commands: goCommands, members: goWorkspaceMembers, version: []string{"go", "version"}, tools: goTools, toolchain: []Tool{goTool, goWorkTool}},
+		&commandProvider{name: "node", detect: isNodeProject, commands: nodeCommands, members: nodeWorkspaceMembers, version: []string{"node", "--version"}, tools: nodeTools, toolchain: append([]Tool{nodeTool}, anyVersion(managerNpm, managerPnpm, managerYarn, managerBun, "corepack")...)},
+		&commandProvider{name: "python", detect: isPythonProject, commands: pythonCommands, version: []string{pythonInterpreter(), "--version"}, tools: pythonTools, toolchain: append([]Tool{pythonTool}, anyVersion("uv", "poetry", "pdm", "hatch", "pipenv")...)},
+		&commandProvider{name: "rust", detect: isRustProject, commands: rustCommands, members: cargoWorkspaceMembers, version: []string{"cargo", "--version"}, toolchain: anyVersion("cargo")},
+		&commandProvider{name: "maven", detect: isMavenProject, commands: mavenCommands, members: mavenModules, version: []string{"java", "-version"}, tools: jvmTools(mavenCommands), toolchain: append(anyVersion("mvn"), javaTool)},
+		&commandProvider{name: "gradle", detect: isGradleProject, commands: gradleCommands, members: gradleMembers, version: []string{"java", "-version"}, tools: jvmTools(gradleCommands), toolchain: append(anyVersion("gradle"), javaTool)},
+	}
+)
+
+// Register adds a provider to the registry, replacing a registered provider of the same name
+func Register(provider Provider) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for i, registered := range registry {
+		if registered.Name() == provider.Name() {
+			registry[i] = provider
+			return
+		}
+	}
+	registry = append(registry, provider)
+}
+
+// Providers returns the registered providers in registration order
+func Providers() []Provider {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	return append([]Provider(nil), registry...)
+}
+
+// Detect returns the registered providers that apply to the repository
+func Detect(repoDir string) []Provider {
+	var detected []Provider
+	for _, provider := range Providers() {
+		if provider.Detect(repoDir) {
+			detected = append(detected, provider)
+		}
+	}
+	return detected
+}
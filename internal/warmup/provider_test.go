@@ -0,0 +1,147 @@
+package warmup
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeProvider records the repositories it warmed up
+type fakeProvider struct {
+	name   string
+	marker string
+	err    error
+	ran    []string
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) Detect(repoDir string) bool { return fileExists(repoDir, p.marker) }
+
+func (p *fakeProvider) Commands(string) [][]string { return [][]string{{p.name}} }
+
+func (p *fakeProvider) Run(repoDir string) error {
+	p.ran = append(p.ran, repoDir)
+	return p.err
+}
+
+// withRegistry replaces the registry for the duration of a test
+func withRegistry(t *testing.T, providers ...Provider) {
+	t.Helper()
+	registryMu.Lock()
+	saved := registry
+	registry = providers
+	registryMu.Unlock()
+
+	t.Cleanup(func() {
+		registryMu.Lock()
+		registry = saved
+		registryMu.Unlock()
+	})
+}
+
+// providerNamed returns the registered provider with the name
+func providerNamed(t *testing.T, name string) Provider {
+	t.Helper()
+	for _, provider := range Providers() {
+		if provider.Name() == name {
+			return provider
+		}
+	}
+	t.Fatalf("Provider %s is not registered", name)
+	return nil
+}
+
+func TestDetect_BuiltinProviders(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, name := range []string{"go.mod", "package.json", "yarn.lock"} {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte("{}"), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	// Go 后端加 Node 前端的仓库同时匹配两个提供者
+	var names []string
+	for _, provider := range Detect(tempDir) {
+		names = append(names, provider.Name())
+	}
+	if strings.Join(names, ",") != "go,node" {
+		t.Errorf("Expected go and node providers, got %v", names)
+	}
+
+	for _, provider := range Providers() {
+		if provider.Name() == "node" {
+			if got := provider.Commands(tempDir); len(got) != 1 || got[0][0] != "yarn" {
+				t.Errorf("Expected yarn for a project with yarn.lock, got %v", got)
+			}
+		}
+	}
+}
+
+func TestPerform_RunsAllDetectedProviders(t *testing.T) {
+	first := &fakeProvider{name: "first", marker: "first.txt", err: errors.New("boom")}
+	second := &fakeProvider{name: "second", marker: "second.txt"}
+	unused := &fakeProvider{name: "unused", marker: "unused.txt"}
+	withRegistry(t, first, second, unused)
+
+	tempDir := t.TempDir()
+	for _, name := range []string{"first.txt", "second.txt"} {
+		if err := os.WriteFile(filepath.Join(tempDir, name), nil, 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	// 一个提供者失败不影响其他提供者执行，错误中包含提供者名称
+	err := Perform(tempDir)
+	if err == nil || !strings.Contains(err.Error(), "first warm-up: boom") {
+		t.Errorf("Expected error from first provider, got %v", err)
+	}
+	if len(first.ran) != 1 || len(second.ran) != 1 {
+		t.Errorf("Expected both detected providers to run, got %v and %v", first.ran, second.ran)
+	}
+	if len(unused.ran) != 0 {
+		t.Errorf("Undetected provider should not run")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	withRegistry(t, &fakeProvider{name: "go"})
+
+	replacement := &fakeProvider{name: "go", marker: "go.work"}
+	Register(replacement)
+	Register(NewCommandProvider("custom", func(string) bool { return true }, func(string) [][]string {
+		return [][]string{{"touch", "custom.txt"}}
+	}))
+
+	// 同名提供者被替换，新提供者追加在末尾
+	providers := Providers()
+	if len(providers) != 2 || providers[0] != replacement || providers[1].Name() != "custom" {
+		t.Fatalf("Unexpected providers: %v", providers)
+	}
+
+	tempDir := t.TempDir()
+	if err := Perform(tempDir); err != nil {
+		t.Fatalf("Perform failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "custom.txt")); err != nil {
+		t.Errorf("Expected custom provider command to run: %v", err)
+	}
+}
+
+func TestPerformWithResults_ProviderOutput(t *testing.T) {
+	withRegistry(t, NewCommandProvider("custom", func(string) bool { return true }, func(string) [][]string {
+		return [][]string{{"echo", "resolved"}, {"sh", "-c", "echo broken; exit 3"}}
+	}))
+
+	// 提供者执行的命令输出记录在结果中，失败时也保留
+	results, err := PerformWithResults(t.TempDir())
+	if err == nil {
+		t.Fatal("Expected failing provider command to fail the warm-up")
+	}
+	if len(results) != 1 || results[0].Err == nil ||
+		!strings.Contains(results[0].Output, "resolved") || !strings.Contains(results[0].Output, "broken") {
+		t.Errorf("Expected provider output in results, got %+v", results)
+	}
+}
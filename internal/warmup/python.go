@@ -0,0 +1,97 @@
+package warmup
+
+import (
+	"path/filepath"
+	"runtime"
+
+	"github.com/BurntSushi/toml"
+)
+
+// VenvDir is the per-repository virtualenv created for Python projects without their own tooling
+const VenvDir = ".venv"
+
+// pythonIndicators are the files marking a Python project
+var pythonIndicators = []string{"requirements.txt", "pyproject.toml", "Pipfile", "setup.py"}
+
+// pyproject holds the parts of pyproject.toml used to choose the installer
+type pyproject struct {
+	Tool map[string]toml.Primitive `toml:"tool"`
+}
+
+// isPythonProject checks if the directory contains a Python project
+func isPythonProject(dir string) bool {
+	for _, indicator := range pythonIndicators {
+		if fileExists(dir, indicator) {
+			return true
+		}
+	}
+	return false
+}
+
+// pythonCommands lists the warm-up commands for Python projects.
+// Project tools (uv, Poetry, PDM, Hatch, Pipenv) install into their own in-project
+// environments; other projects get a .venv so nothing is installed into the global interpreter.
+func pythonCommands(repoDir string) [][]string {
+	tools := pyprojectTools(repoDir)
+
+	switch {
+	case fileExists(repoDir, "uv.lock"):
+		return [][]string{{"uv", "sync", "--frozen"}}
+	case tools["uv"]:
+		return [][]string{{"uv", "sync"}}
+	case fileExists(repoDir, "poetry.lock") || tools["poetry"]:
+		return [][]string{{"env", "POETRY_VIRTUALENVS_IN_PROJECT=true", "poetry", "install"}}
+	case fileExists(repoDir, "pdm.lock"):
+		return [][]string{{"pdm", "install", "--frozen-lockfile"}}
+	case tools["pdm"]:
+		return [][]string{{"pdm", "install"}}
+	case fileExists(repoDir, "hatch.toml") || tools["hatch"]:
+		return [][]string{{"hatch", "env", "create"}}
+	case fileExists(repoDir, "Pipfile.lock"):
+		return [][]string{{"env", "PIPENV_VENV_IN_PROJECT=1", "pipenv", "sync"}}
+	case fileExists(repoDir, "Pipfile"):
+		return [][]string{{"env", "PIPENV_VENV_IN_PROJECT=1", "pipenv", "install"}}
+	}
+
+	var commands [][]string
+	if !fileExists(repoDir, VenvDir) {
+		commands = append(commands, []string{pythonInterpreter(), "-m", "venv", VenvDir})
+	}
+	pip := []string{venvPython(), "-m", "pip", "install"}
+	if fileExists(repoDir, "requirements.txt") {
+		commands = append(commands, append(pip, "-r", "requirements.txt"))
+	}
+	if fileExists(repoDir, "pyproject.toml") || fileExists(repoDir, "setup.py") {
+		commands = append(commands, append(pip, "-e", "."))
+	}
+	return commands
+}
+
+// pyprojectTools returns the [tool.*] tables of pyproject.toml
+func pyprojectTools(repoDir string) map[string]bool {
+	var project pyproject
+	if _, err := toml.DecodeFile(filepath.Join(repoDir, "pyproject.toml"), &project); err != nil {
+		return nil
+	}
+	tools := make(map[string]bool, len(project.Tool))
+	for name := range project.Tool {
+		tools[name] = true
+	}
+	return tools
+}
+
+// pythonInterpreter is the interpreter used to create virtualenvs
+func pythonInterpreter() string {
+	if runtime.GOOS == "windows" {
+		return "python"
+	}
+	return "python3"
+}
+
+// venvPython is the interpreter of the virtualenv, relative to the repository directory
+func venvPython() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(VenvDir, "Scripts", "python.exe")
+	}
+	return filepath.Join(VenvDir, "bin", "python")
+}
@@ -0,0 +1,107 @@
+package warmup
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPythonCommands(t *testing.T) {
+	venv := []string{pythonInterpreter(), "-m", "venv", VenvDir}
+	pip := []string{venvPython(), "-m", "pip", "install"}
+
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected [][]string
+	}{
+		{
+			name:     "requirements.txt",
+			files:    map[string]string{"requirements.txt": "requests\n"},
+			expected: [][]string{venv, append(pip, "-r", "requirements.txt")},
+		},
+		{
+			name:     "existing virtualenv",
+			files:    map[string]string{"requirements.txt": "requests\n", ".venv/pyvenv.cfg": ""},
+			expected: [][]string{append(pip, "-r", "requirements.txt")},
+		},
+		{
+			name:     "setup.py",
+			files:    map[string]string{"setup.py": "from setuptools import setup\nsetup()\n"},
+			expected: [][]string{venv, append(pip, "-e", ".")},
+		},
+		{
+			name:     "pyproject.toml without tool",
+			files:    map[string]string{"pyproject.toml": "[project]\nname = \"lib\"\n"},
+			expected: [][]string{venv, append(pip, "-e", ".")},
+		},
+		{
+			name:     "uv lockfile",
+			files:    map[string]string{"pyproject.toml": "[project]\nname = \"lib\"\n", "uv.lock": ""},
+			expected: [][]string{{"uv", "sync", "--frozen"}},
+		},
+		{
+			name:     "uv tool table",
+			files:    map[string]string{"pyproject.toml": "[tool.uv]\ndev-dependencies = []\n"},
+			expected: [][]string{{"uv", "sync"}},
+		},
+		{
+			name:     "poetry",
+			files:    map[string]string{"pyproject.toml": "[tool.poetry]\nname = \"app\"\n"},
+			expected: [][]string{{"env", "POETRY_VIRTUALENVS_IN_PROJECT=true", "poetry", "install"}},
+		},
+		{
+			name:     "pdm lockfile",
+			files:    map[string]string{"pyproject.toml": "[tool.pdm]\n", "pdm.lock": ""},
+			expected: [][]string{{"pdm", "install", "--frozen-lockfile"}},
+		},
+		{
+			name:     "hatch",
+			files:    map[string]string{"pyproject.toml": "[tool.hatch.envs.default]\n"},
+			expected: [][]string{{"hatch", "env", "create"}},
+		},
+		{
+			name:     "pipenv lockfile",
+			files:    map[string]string{"Pipfile": "[packages]\n", "Pipfile.lock": "{}"},
+			expected: [][]string{{"env", "PIPENV_VENV_IN_PROJECT=1", "pipenv", "sync"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, test.files)
+
+			if !isPythonProject(dir) {
+				t.Errorf("Expected a Python project")
+			}
+			if got := pythonCommands(dir); !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestWarmUpPython_CreatesVirtualenv(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping virtualenv creation in short mode")
+	}
+	if _, err := exec.LookPath(pythonInterpreter()); err != nil {
+		t.Skip("Python not available, skipping test")
+	}
+
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "requirements.txt"), nil, 0644); err != nil {
+		t.Fatalf("Failed to create requirements.txt: %v", err)
+	}
+
+	// 依赖安装到仓库内的 .venv，而不是全局解释器
+	if err := providerNamed(t, "python").Run(tempDir); err != nil {
+		t.Fatalf("python provider failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, venvPython())); err != nil {
+		t.Errorf("Expected virtualenv interpreter: %v", err)
+	}
+}
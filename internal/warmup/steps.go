@@ -0,0 +1,136 @@
+package warmup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Step is a warm-up command with its own working directory, environment and timeout
+type Step struct {
+	// Command is the program and its arguments
+	Command []string
+	// Dir is the working directory, relative to the repository directory
+	Dir string
+	// Env holds KEY=VALUE pairs added to the environment
+	Env []string
+	// Timeout stops the command after the given duration (0 for no limit)
+	Timeout time.Duration
+}
+
+// StepResult records the outcome of one warm-up step
+type StepResult struct {
+	// Name describes the step, e.g. the command line or the provider name
+	Name     string
+	Duration time.Duration
+	Output   string
+	Err      error
+	// Skipped explains why the step did not run, e.g. a missing toolchain
+	Skipped string
+}
+
+// RunSteps executes warm-up steps in order inside the repository directory.
+// It stops at the first failing step; the results include that step.
+func RunSteps(repoDir string, steps []Step) ([]StepResult, error) {
+	results := make([]StepResult, 0, len(steps))
+	for _, step := range steps {
+		result := runStep(repoDir, step)
+		results = append(results, result)
+		if result.Err != nil {
+			return results, fmt.Errorf("%s failed: %w", result.Name, result.Err)
+		}
+	}
+	return results, nil
+}
+
+// runStep executes a single warm-up step, capturing its combined output
+func runStep(repoDir string, step Step) StepResult {
+	result := StepResult{Name: strings.Join(step.Command, " ")}
+	if len(step.Command) == 0 {
+		result.Err = errors.New("empty warm-up command")
+		return result
+	}
+
+	ctx := context.Background()
+	if step.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, step.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, step.Command[0], step.Command[1:]...)
+	cmd.Dir = repoDir
+	if step.Dir != "" {
+		cmd.Dir = step.Dir
+		if !filepath.IsAbs(step.Dir) {
+			cmd.Dir = filepath.Join(repoDir, step.Dir)
+		}
+	}
+	if len(step.Env) > 0 {
+		cmd.Env = append(os.Environ(), step.Env...)
+	}
+	// Keep waiting for output only briefly once a timed out command is killed
+	cmd.WaitDelay = time.Second
+
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	start := time.Now()
+	err := cmd.Run()
+	result.Duration = time.Since(start)
+	result.Output = output.String()
+
+	if ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("timed out after %v", step.Timeout)
+	}
+	result.Err = err
+	return result
+}
+
+// PerformWithResults runs every provider detected in the repository and its subdirectories
+// like Perform, recording one result per project with the output of providers implementing
+// OutputProvider. Projects whose toolchain is missing are skipped rather than failed.
+func PerformWithResults(repoDir string) ([]StepResult, error) {
+	var results []StepResult
+	var errs []error
+	for _, project := range DetectProjects(repoDir, MaxProjectDepth) {
+		name := project.Provider.Name()
+		if rel, err := filepath.Rel(repoDir, project.Dir); err == nil && rel != "." {
+			name += " (" + filepath.ToSlash(rel) + ")"
+		}
+
+		if toolchain, ok := project.Provider.(ToolchainProvider); ok {
+			if err := CheckTools(toolchain.RequiredTools(project.Dir)); err != nil {
+				results = append(results, StepResult{Name: name, Skipped: err.Error()})
+				continue
+			}
+		}
+
+		start := time.Now()
+		var output string
+		var err error
+		if withOutput, ok := project.Provider.(OutputProvider); ok {
+			output, err = withOutput.RunWithOutput(project.Dir)
+		} else {
+			err = project.Provider.Run(project.Dir)
+		}
+		results = append(results, StepResult{
+			Name:     name,
+			Duration: time.Since(start),
+			Output:   output,
+			Err:      err,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s warm-up: %w", name, err))
+		}
+	}
+	// No project detected is fine, there is just nothing to warm up
+	return results, errors.Join(errs...)
+}
@@ -0,0 +1,71 @@
+package warmup
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunSteps(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(tempDir, "sub"), 0755); err != nil {
+		t.Fatalf("Failed to create sub directory: %v", err)
+	}
+
+	results, err := RunSteps(tempDir, []Step{
+		{Command: []string{"sh", "-c", "echo $GREETING; pwd"}, Dir: "sub", Env: []string{"GREETING=hello"}},
+		{Command: []string{"touch", "done.txt"}},
+	})
+	if err != nil {
+		t.Fatalf("RunSteps failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+
+	// 输出被捕获，工作目录和环境变量生效
+	if !strings.Contains(results[0].Output, "hello") || !strings.Contains(results[0].Output, "sub") {
+		t.Errorf("Unexpected output: %q", results[0].Output)
+	}
+	if results[1].Name != "touch done.txt" {
+		t.Errorf("Unexpected step name: %q", results[1].Name)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "done.txt")); err != nil {
+		t.Errorf("Expected step to run in the repository directory: %v", err)
+	}
+}
+
+func TestRunSteps_StopsAtFailure(t *testing.T) {
+	tempDir := t.TempDir()
+
+	results, err := RunSteps(tempDir, []Step{
+		{Command: []string{"sh", "-c", "echo broken >&2; exit 3"}},
+		{Command: []string{"touch", "skipped.txt"}},
+	})
+	if err == nil {
+		t.Fatal("Expected error for failing step")
+	}
+
+	// 失败的步骤记录在结果中，后续步骤不再执行
+	if len(results) != 1 || results[0].Err == nil || !strings.Contains(results[0].Output, "broken") {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "skipped.txt")); err == nil {
+		t.Error("Steps after a failure should not run")
+	}
+}
+
+func TestRunSteps_Timeout(t *testing.T) {
+	start := time.Now()
+	results, err := RunSteps(t.TempDir(), []Step{
+		{Command: []string{"sleep", "10"}, Timeout: 100 * time.Millisecond},
+	})
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Expected timeout error, got %v", err)
+	}
+	if len(results) != 1 || time.Since(start) > 5*time.Second {
+		t.Errorf("Step was not stopped at its timeout: %+v", results)
+	}
+}
@@ -0,0 +1,237 @@
+package warmup
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Tool is an executable a warm-up needs on PATH
+type Tool struct {
+	// Name is the executable, e.g. "cargo"
+	Name string
+	// MinVersion is the oldest supported version, e.g. "1.18" ("" accepts any version)
+	MinVersion string
+	// VersionArgs make the tool print its version; required with MinVersion
+	VersionArgs []string
+}
+
+// ToolchainProvider is implemented by providers that declare the tools their warm-up needs
+type ToolchainProvider interface {
+	Provider
+	// RequiredTools lists the tools needed to warm up the project in dir
+	RequiredTools(dir string) []Tool
+	// Toolchain lists every tool the provider may require, resolved before warm-ups start
+	Toolchain() []Tool
+}
+
+// MissingToolchainError reports a required tool that is not installed or too old
+type MissingToolchainError struct {
+	Tool   string
+	Reason string
+}
+
+func (e *MissingToolchainError) Error() string {
+	return fmt.Sprintf("missing toolchain %s: %s", e.Tool, e.Reason)
+}
+
+// Minimum versions of the toolchains behind the built-in providers
+var (
+	// goTool requires module support
+	goTool = Tool{Name: "go", MinVersion: "1.11", VersionArgs: []string{"version"}}
+	// goWorkTool requires workspace support
+	goWorkTool = Tool{Name: "go", MinVersion: "1.18", VersionArgs: []string{"version"}}
+	// nodeTool requires the first release bundling an npm with npm ci
+	nodeTool = Tool{Name: "node", MinVersion: "10", VersionArgs: []string{"--version"}}
+	// pythonTool requires ensurepip, so that new virtualenvs come with pip
+	pythonTool = Tool{Name: pythonInterpreter(), MinVersion: "3.4", VersionArgs: []string{"--version"}}
+	// javaTool requires the JDK current Maven and Gradle releases run on
+	javaTool = Tool{Name: "java", MinVersion: "1.8", VersionArgs: []string{"-version"}}
+)
+
+// versionPattern matches the first dotted version number in version output
+var versionPattern = regexp.MustCompile(`\d+(\.\d+)+`)
+
+// toolChecks caches tool checks by PATH for the lifetime of the process
+var toolChecks sync.Map
+
+// CheckTools verifies that the tools are installed in a supported version, returning a
+// *MissingToolchainError for the first one that is not. Each tool is checked once per process and PATH.
+func CheckTools(tools []Tool) error {
+	for _, tool := range tools {
+		key := os.Getenv("PATH") + "\x00" + tool.Name + "@" + tool.MinVersion
+		result, ok := toolChecks.Load(key)
+		if !ok {
+			result, _ = toolChecks.LoadOrStore(key, checkTool(tool))
+		}
+		if err, _ := result.(error); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResolveToolchains checks the toolchains of all registered providers concurrently, so that
+// warm-ups find the results cached. It returns the tools that are missing or too old.
+func ResolveToolchains() []error {
+	var tools []Tool
+	seen := make(map[string]bool)
+	for _, provider := range Providers() {
+		toolchain, ok := provider.(ToolchainProvider)
+		if !ok {
+			continue
+		}
+		for _, tool := range toolchain.Toolchain() {
+			if key := tool.Name + "@" + tool.MinVersion; !seen[key] {
+				seen[key] = true
+				tools = append(tools, tool)
+			}
+		}
+	}
+
+	errs := make([]error, len(tools))
+	var wg sync.WaitGroup
+	for i, tool := range tools {
+		wg.Add(1)
+		go func(i int, tool Tool) {
+			defer wg.Done()
+			errs[i] = CheckTools([]Tool{tool})
+		}(i, tool)
+	}
+	wg.Wait()
+
+	var missing []error
+	for _, err := range errs {
+		if err != nil {
+			missing = append(missing, err)
+		}
+	}
+	return missing
+}
+
+// checkTool looks a tool up on PATH and compares its version with the minimum version
+func checkTool(tool Tool) error {
+	path, err := exec.LookPath(tool.Name)
+	if err != nil {
+		return &MissingToolchainError{Tool: tool.Name, Reason: "not found on PATH"}
+	}
+	if tool.MinVersion == "" {
+		return nil
+	}
+
+	output, err := exec.Command(path, tool.VersionArgs...).CombinedOutput()
+	if err != nil {
+		return &MissingToolchainError{Tool: tool.Name, Reason: "version check failed"}
+	}
+	version := versionPattern.FindString(string(output))
+	if version == "" {
+		// Unknown version formats are given the benefit of the doubt
+		return nil
+	}
+	if compareVersions(version, tool.MinVersion) < 0 {
+		return &MissingToolchainError{Tool: tool.Name, Reason: fmt.Sprintf("version %s is older than %s", version, tool.MinVersion)}
+	}
+	return nil
+}
+
+// compareVersions compares dotted numeric versions, treating missing parts as 0
+func compareVersions(a, b string) int {
+	aParts, bParts := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var x, y int
+		if i < len(aParts) {
+			x, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			y, _ = strconv.Atoi(bParts[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// commandTools returns the executables run by warm-up commands, skipping env assignments
+// and scripts inside the project such as ./mvnw or .venv/bin/python
+func commandTools(commands [][]string) []Tool {
+	var tools []Tool
+	seen := make(map[string]bool)
+	for _, command := range commands {
+		name := commandExecutable(command)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		tools = append(tools, Tool{Name: name})
+	}
+	return tools
+}
+
+// commandExecutable returns the program a command runs, looking through env wrappers
+func commandExecutable(command []string) string {
+	for i, arg := range command {
+		if i == 0 && arg == "env" {
+			continue
+		}
+		if i > 0 && command[0] == "env" && strings.Contains(arg, "=") {
+			continue
+		}
+		if strings.ContainsAny(arg, `/\`) {
+			return ""
+		}
+		return arg
+	}
+	return ""
+}
+
+// goTools requires Go modules, and Go 1.18 for workspaces
+func goTools(repoDir string) []Tool {
+	if fileExists(repoDir, "go.work") {
+		return []Tool{goWorkTool}
+	}
+	return []Tool{goTool}
+}
+
+// nodeTools requires the package manager, or corepack, and Node.js itself unless bun runs the install
+func nodeTools(repoDir string) []Tool {
+	tools := commandTools(nodeCommands(repoDir))
+	if len(tools) > 0 && tools[0].Name == managerBun {
+		return tools
+	}
+	return append(tools, nodeTool)
+}
+
+// pythonTools requires the interpreter in a supported version when it creates the virtualenv
+func pythonTools(repoDir string) []Tool {
+	tools := commandTools(pythonCommands(repoDir))
+	for i, tool := range tools {
+		if tool.Name == pythonTool.Name {
+			tools[i] = pythonTool
+		}
+	}
+	return tools
+}
+
+// jvmTools requires the build tool, unless a wrapper provides it, and a JDK
+func jvmTools(commands func(repoDir string) [][]string) func(repoDir string) []Tool {
+	return func(repoDir string) []Tool {
+		return append(commandTools(commands(repoDir)), javaTool)
+	}
+}
+
+// anyVersion lists tools accepted in any version
+func anyVersion(names ...string) []Tool {
+	tools := make([]Tool, len(names))
+	for i, name := range names {
+		tools[i] = Tool{Name: name}
+	}
+	return tools
+}
@@ -0,0 +1,193 @@
+package warmup
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// toolchainProvider requires a tool before it warms up
+type toolchainProvider struct {
+	fakeProvider
+	tools []Tool
+}
+
+func (p *toolchainProvider) RequiredTools(string) []Tool { return p.tools }
+
+func (p *toolchainProvider) Toolchain() []Tool { return p.tools }
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.22.1", "1.18", 1},
+		{"1.18", "1.18.0", 0},
+		{"1.9", "1.18", -1},
+		{"2", "1.99.99", 1},
+	}
+
+	for _, test := range tests {
+		if got := compareVersions(test.a, test.b); got != test.expected {
+			t.Errorf("compareVersions(%q, %q) = %d, expected %d", test.a, test.b, got, test.expected)
+		}
+	}
+}
+
+func TestCommandTools(t *testing.T) {
+	commands := [][]string{
+		{"env", "POETRY_VIRTUALENVS_IN_PROJECT=true", "poetry", "install"},
+		{"./mvnw", "-B"},
+		{".venv/bin/python", "-m", "pip", "install"},
+		{"sh", "gradlew", "dependencies"},
+		{"poetry", "check"},
+	}
+
+	// env 包装的命令取实际程序，项目内的脚本不需要检查
+	expected := []Tool{{Name: "poetry"}, {Name: "sh"}}
+	if got := commandTools(commands); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestCheckTool(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Shell script tools are not supported on Windows")
+	}
+
+	// 用打印版本号的脚本模拟工具
+	binDir := t.TempDir()
+	script := "#!/bin/sh\necho fake version 1.2.3\n"
+	if err := os.WriteFile(filepath.Join(binDir, "fake-tool"), []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write tool: %v", err)
+	}
+	t.Setenv("PATH", binDir)
+
+	var missing *MissingToolchainError
+	err := checkTool(Tool{Name: "repoll-missing-tool"})
+	if !errors.As(err, &missing) || missing.Tool != "repoll-missing-tool" || !strings.Contains(err.Error(), "not found on PATH") {
+		t.Errorf("Expected missing toolchain error, got %v", err)
+	}
+
+	if err := checkTool(Tool{Name: "fake-tool", MinVersion: "1.2", VersionArgs: []string{"--version"}}); err != nil {
+		t.Errorf("Expected version 1.2.3 to satisfy 1.2, got %v", err)
+	}
+	err = checkTool(Tool{Name: "fake-tool", MinVersion: "1.10", VersionArgs: []string{"--version"}})
+	if !errors.As(err, &missing) || !strings.Contains(err.Error(), "version 1.2.3 is older than 1.10") {
+		t.Errorf("Expected outdated toolchain error, got %v", err)
+	}
+}
+
+func TestResolveToolchains(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Shell script tools are not supported on Windows")
+	}
+
+	// 脚本每次运行都记一笔，用来确认版本只检查一次
+	binDir := t.TempDir()
+	calls := filepath.Join(binDir, "calls")
+	script := "#!/bin/sh\necho run >> " + calls + "\necho resolve-tool 2.0.1\n"
+	if err := os.WriteFile(filepath.Join(binDir, "repoll-resolve-tool"), []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write tool: %v", err)
+	}
+	t.Setenv("PATH", binDir)
+
+	present := Tool{Name: "repoll-resolve-tool", MinVersion: "2.0", VersionArgs: []string{"--version"}}
+	withRegistry(t, &toolchainProvider{
+		fakeProvider: fakeProvider{name: "resolve", marker: "resolve.txt"},
+		tools:        []Tool{present, {Name: "repoll-unresolved-tool"}},
+	})
+
+	missing := ResolveToolchains()
+	if len(missing) != 1 || !strings.Contains(missing[0].Error(), "missing toolchain repoll-unresolved-tool") {
+		t.Errorf("Expected the unresolved tool to be reported, got %v", missing)
+	}
+	if err := CheckTools([]Tool{present}); err != nil {
+		t.Errorf("Expected the resolved tool to pass, got %v", err)
+	}
+	data, err := os.ReadFile(calls)
+	if err != nil {
+		t.Fatalf("Failed to read calls: %v", err)
+	}
+	if got := strings.Count(string(data), "run"); got != 1 {
+		t.Errorf("Expected the version to be checked once, got %d", got)
+	}
+}
+
+func TestBuiltinToolchain(t *testing.T) {
+	versions := make(map[string]string)
+	for _, provider := range Providers() {
+		if toolchain, ok := provider.(ToolchainProvider); ok {
+			for _, tool := range toolchain.Toolchain() {
+				if tool.MinVersion != "" && (versions[tool.Name] == "" || compareVersions(tool.MinVersion, versions[tool.Name]) < 0) {
+					versions[tool.Name] = tool.MinVersion
+				}
+			}
+		}
+	}
+
+	// 内置生态都声明了最低版本
+	expected := map[string]string{"go": "1.11", "node": "10", pythonInterpreter(): "3.4", "java": "1.8"}
+	if !reflect.DeepEqual(versions, expected) {
+		t.Errorf("Expected minimum versions %v, got %v", expected, versions)
+	}
+}
+
+func TestPerformWithResults_MissingToolchain(t *testing.T) {
+	missing := &toolchainProvider{
+		fakeProvider: fakeProvider{name: "missing", marker: "missing.txt"},
+		tools:        []Tool{{Name: "repoll-missing-tool"}},
+	}
+	present := &toolchainProvider{
+		fakeProvider: fakeProvider{name: "present", marker: "present.txt"},
+		tools:        []Tool{{Name: "go"}},
+	}
+	withRegistry(t, missing, present)
+
+	repoDir := t.TempDir()
+	writeFiles(t, repoDir, map[string]string{"missing.txt": "", "present.txt": ""})
+
+	// 缺少工具链的提供者被跳过，不算作失败
+	results, err := PerformWithResults(repoDir)
+	if err != nil {
+		t.Fatalf("Expected no error for a missing toolchain, got %v", err)
+	}
+	if len(results) != 2 || results[0].Skipped != "missing toolchain repoll-missing-tool: not found on PATH" || results[1].Skipped != "" {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+	if len(missing.ran) != 0 || len(present.ran) != 1 {
+		t.Errorf("Expected only the provider with its toolchain to run, got %v and %v", missing.ran, present.ran)
+	}
+}
+
+func TestBuiltinRequiredTools(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.work":      "go 1.22\n",
+		"package.json": `{"name": "web"}`,
+		"bun.lockb":    "",
+		"pom.xml":      "<project/>",
+	})
+
+	tools := make(map[string][]Tool)
+	for _, provider := range Providers() {
+		if toolchain, ok := provider.(ToolchainProvider); ok && provider.Detect(root) {
+			tools[provider.Name()] = toolchain.RequiredTools(root)
+		}
+	}
+
+	// Go 工作区要求 1.18 以上，bun 不需要 node，Maven 需要 JDK
+	if got := tools["go"]; len(got) != 1 || got[0].MinVersion != "1.18" {
+		t.Errorf("Unexpected go tools: %v", got)
+	}
+	if got := tools["node"]; !reflect.DeepEqual(got, []Tool{{Name: "bun"}}) {
+		t.Errorf("Unexpected node tools: %v", got)
+	}
+	if got := tools["maven"]; !reflect.DeepEqual(got, []Tool{{Name: "mvn"}, javaTool}) {
+		t.Errorf("Unexpected maven tools: %v", got)
+	}
+}
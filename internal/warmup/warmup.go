@@ -5,125 +5,66 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 )
 
-// Perform executes warm-up operations based on detected project type
+// Perform executes the warm-up of every project detected in the repository and its subdirectories
 func Perform(repoDir string) error {
-	// Detect project type and run appropriate warm-up commands
-	if isGoProject(repoDir) {
-		return warmUpGo(repoDir)
-	}
-
-	if isNodeProject(repoDir) {
-		return warmUpNode(repoDir)
-	}
-
-	if isPythonProject(repoDir) {
-		return warmUpPython(repoDir)
-	}
+	_, err := PerformWithResults(repoDir)
+	return err
+}
 
-	if isRustProject(repoDir) {
-		return warmUpRust(repoDir)
+// RunCommands executes custom warm-up commands in order inside the repository directory
+func RunCommands(repoDir string, commands [][]string) error {
+	for _, command := range commands {
+		if len(command) == 0 {
+			return fmt.Errorf("empty warm-up command")
+		}
+		cmd := exec.Command(command[0], command[1:]...)
+		cmd.Dir = repoDir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%s failed: %w\nOutput: %s", strings.Join(command, " "), err, string(output))
+		}
 	}
-
-	// No specific project type detected, but that's okay
 	return nil
 }
 
+// fileExists reports whether a file exists in the directory
+func fileExists(dir, name string) bool {
+	_, err := os.Stat(filepath.Join(dir, name))
+	return err == nil
+}
+
 // isGoProject checks if the directory contains a Go project
 func isGoProject(dir string) bool {
-	goModPath := filepath.Join(dir, "go.mod")
-	_, err := os.Stat(goModPath)
-	return err == nil
+	return fileExists(dir, "go.mod")
 }
 
-// isNodeProject checks if the directory contains a Node.js project
-func isNodeProject(dir string) bool {
-	packagePath := filepath.Join(dir, "package.json")
-	_, err := os.Stat(packagePath)
-	return err == nil
+// isGoWorkspaceOrProject checks if the directory contains a Go workspace or module
+func isGoWorkspaceOrProject(dir string) bool {
+	return fileExists(dir, "go.work") || isGoProject(dir)
 }
 
-// isPythonProject checks if the directory contains a Python project
-func isPythonProject(dir string) bool {
-	requirementsPath := filepath.Join(dir, "requirements.txt")
-	_, err := os.Stat(requirementsPath)
-	return err == nil
+// isNodeProject checks if the directory contains a Node.js project
+func isNodeProject(dir string) bool {
+	return fileExists(dir, "package.json")
 }
 
 // isRustProject checks if the directory contains a Rust project
 func isRustProject(dir string) bool {
-	cargoPath := filepath.Join(dir, "Cargo.toml")
-	_, err := os.Stat(cargoPath)
-	return err == nil
-}
-
-// warmUpGo performs warm-up for Go projects
-func warmUpGo(repoDir string) error {
-	// Run go mod download
-	cmd := exec.Command("go", "mod", "download")
-	cmd.Dir = repoDir
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("go mod download failed: %w\nOutput: %s", err, string(output))
-	}
-
-	// Run go mod tidy
-	cmd = exec.Command("go", "mod", "tidy")
-	cmd.Dir = repoDir
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("go mod tidy failed: %w\nOutput: %s", err, string(output))
-	}
-
-	return nil
-}
-
-// warmUpNode performs warm-up for Node.js projects
-func warmUpNode(repoDir string) error {
-	// Check if yarn.lock exists, prefer yarn over npm
-	yarnLockPath := filepath.Join(repoDir, "yarn.lock")
-	if _, err := os.Stat(yarnLockPath); err == nil {
-		return warmUpWithYarn(repoDir)
-	}
-
-	return warmUpWithNpm(repoDir)
-}
-
-// warmUpWithYarn performs warm-up using Yarn
-func warmUpWithYarn(repoDir string) error {
-	cmd := exec.Command("yarn", "install")
-	cmd.Dir = repoDir
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("yarn install failed: %w\nOutput: %s", err, string(output))
-	}
-	return nil
-}
-
-// warmUpWithNpm performs warm-up using npm
-func warmUpWithNpm(repoDir string) error {
-	cmd := exec.Command("npm", "install")
-	cmd.Dir = repoDir
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("npm install failed: %w\nOutput: %s", err, string(output))
-	}
-	return nil
+	return fileExists(dir, "Cargo.toml")
 }
 
-// warmUpPython performs warm-up for Python projects
-func warmUpPython(repoDir string) error {
-	cmd := exec.Command("pip", "install", "-r", "requirements.txt")
-	cmd.Dir = repoDir
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("pip install failed: %w\nOutput: %s", err, string(output))
-	}
-	return nil
+// goCommands lists the warm-up commands for Go projects. Only downloads run:
+// go mod tidy would rewrite go.mod and go.sum of a fresh clone.
+func goCommands(string) [][]string {
+	return [][]string{{"go", "mod", "download"}}
 }
 
-// warmUpRust performs warm-up for Rust projects
-func warmUpRust(repoDir string) error {
-	cmd := exec.Command("cargo", "fetch")
-	cmd.Dir = repoDir
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("cargo fetch failed: %w\nOutput: %s", err, string(output))
+// rustCommands lists the warm-up commands for Rust projects, keeping an existing Cargo.lock unchanged
+func rustCommands(repoDir string) [][]string {
+	if fileExists(repoDir, "Cargo.lock") {
+		return [][]string{{"cargo", "fetch", "--locked"}}
 	}
-	return nil
+	return [][]string{{"cargo", "fetch"}}
 }
@@ -178,7 +178,7 @@ go 1.21
 		t.Fatalf("Failed to create go.mod: %v", err)
 	}
 	
-	// 测试warmup（应该检测到Go项目并优先执行）
+	// 测试warmup（Go和Node两个提供者都应执行）
 	err = Perform(tempDir)
 	// 不严格检查错误，因为命令可能不存在
 	t.Logf("Perform with multiple project types result: %v", err)
@@ -294,9 +294,9 @@ func TestWarmUpWithYarn_ValidProject(t *testing.T) {
 		t.Fatalf("Failed to create yarn.lock: %v", err)
 	}
 	
-	err = warmUpWithYarn(tempDir)
+	err = providerNamed(t, "node").Run(tempDir)
 	// 可能失败但不应该panic
-	t.Logf("warmUpWithYarn result: %v", err)
+	t.Logf("node provider result: %v", err)
 }
 
 func TestWarmUpWithNpm_ValidProject(t *testing.T) {
@@ -310,9 +310,9 @@ func TestWarmUpWithNpm_ValidProject(t *testing.T) {
 		t.Fatalf("Failed to create package.json: %v", err)
 	}
 	
-	err = warmUpWithNpm(tempDir)
+	err = providerNamed(t, "node").Run(tempDir)
 	// 可能失败但不应该panic
-	t.Logf("warmUpWithNpm result: %v", err)
+	t.Logf("node provider result: %v", err)
 }
 
 func TestWarmUpGo_ValidProject(t *testing.T) {
@@ -328,9 +328,9 @@ go 1.21
 		t.Fatalf("Failed to create go.mod: %v", err)
 	}
 	
-	err = warmUpGo(tempDir)
+	err = providerNamed(t, "go").Run(tempDir)
 	// 可能失败但不应该panic
-	t.Logf("warmUpGo result: %v", err)
+	t.Logf("go provider result: %v", err)
 }
 
 func TestWarmUpPython_ValidProject(t *testing.T) {
@@ -344,9 +344,9 @@ func TestWarmUpPython_ValidProject(t *testing.T) {
 		t.Fatalf("Failed to create requirements.txt: %v", err)
 	}
 	
-	err = warmUpPython(tempDir)
+	err = providerNamed(t, "python").Run(tempDir)
 	// 可能失败但不应该panic
-	t.Logf("warmUpPython result: %v", err)
+	t.Logf("python provider result: %v", err)
 }
 
 func TestWarmUpRust_ValidProject(t *testing.T) {
@@ -363,7 +363,30 @@ version = "0.1.0"
 		t.Fatalf("Failed to create Cargo.toml: %v", err)
 	}
 	
-	err = warmUpRust(tempDir)
+	err = providerNamed(t, "rust").Run(tempDir)
 	// 可能失败但不应该panic
-	t.Logf("warmUpRust result: %v", err)
-} 
\ No newline at end of file
+	t.Logf("rust provider result: %v", err)
+} 
+func TestRunCommands(t *testing.T) {
+	tempDir := t.TempDir()
+
+	err := RunCommands(tempDir, [][]string{{"touch", "a.txt"}, {"touch", "b.txt"}})
+	if err != nil {
+		t.Fatalf("RunCommands failed: %v", err)
+	}
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if _, err := os.Stat(filepath.Join(tempDir, name)); err != nil {
+			t.Errorf("Expected %s to be created: %v", name, err)
+		}
+	}
+
+	// 失败的命令应返回错误，并包含命令内容
+	err = RunCommands(tempDir, [][]string{{"false"}})
+	if err == nil {
+		t.Error("Expected error for failing command")
+	}
+
+	if err := RunCommands(tempDir, [][]string{{}}); err == nil {
+		t.Error("Expected error for empty command")
+	}
+}